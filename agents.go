@@ -0,0 +1,93 @@
+package dialogflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// ErrUnknownAgent is returned by the AgentMux when no handler is registered
+// for the agent the request comes from
+var ErrUnknownAgent = errors.New("dialogflow: unknown agent")
+
+// AgentMux dispatches incoming requests to a handler according to the
+// agent they come from, which allows to host multiple agents behind a single
+// webhook. The agent is identified by the project ID, and optionally the
+// environment, found in the session of the request.
+// AgentMux implements the http.Handler interface.
+type AgentMux struct {
+	// Fallback builds the fulfillment sent back when an agent's handler
+	// returns an error other than ErrNoHandler. DefaultFallback is used if
+	// nil.
+	Fallback FallbackFunc
+	// DecodeOptions are the options used to decode incoming requests
+	DecodeOptions DecodeOptions
+	// ErrorLog is an optional logger for requests that couldn't be decoded
+	ErrorLog *log.Logger
+
+	agents map[string]HandlerFunc
+}
+
+// NewAgentMux returns a new empty AgentMux
+func NewAgentMux() *AgentMux {
+	return &AgentMux{agents: make(map[string]HandlerFunc)}
+}
+
+// HandleAgent registers the handler for every environment of the given
+// project. The handler is often a Router's Handle method.
+func (m *AgentMux) HandleAgent(project string, h HandlerFunc) {
+	m.agents[project] = h
+}
+
+// HandleAgentEnvironment registers the handler for a specific environment of
+// the given project. It takes precedence over the handler registered with
+// HandleAgent for the same project.
+func (m *AgentMux) HandleAgentEnvironment(project, env string, h HandlerFunc) {
+	m.agents[project+"/"+env] = h
+}
+
+// Handle dispatches the request to the handler of the agent it comes from.
+// An error wrapping ErrUnknownAgent is returned if there is none. Errors
+// returned by the handler are turned into a fulfillment using the mux's
+// Fallback.
+// Handle has the HandlerFunc signature.
+func (m *AgentMux) Handle(ctx context.Context, req *Request) (*Fulfillment, error) {
+	sp, err := req.SessionPath()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownAgent, err)
+	}
+	project, env := sp.ProjectID, sp.Environment
+	if h, ok := m.agents[project+"/"+env]; ok && env != "" {
+		return m.handle(ctx, req, h)
+	}
+	if h, ok := m.agents[project]; ok {
+		return m.handle(ctx, req, h)
+	}
+	if env != "" {
+		return nil, fmt.Errorf("%w: project %q, environment %q", ErrUnknownAgent, project, env)
+	}
+	return nil, fmt.Errorf("%w: project %q", ErrUnknownAgent, project)
+}
+
+// handle calls the handler of an agent, turning its errors into a fulfillment
+// using the mux's Fallback. ErrNoHandler is kept as is, so that a Router
+// without a matching handler still results in a 404.
+func (m *AgentMux) handle(ctx context.Context, req *Request, h HandlerFunc) (*Fulfillment, error) {
+	dff, err := h(WithRequest(ctx, req), req)
+	if err != nil && !errors.Is(err, ErrNoHandler) {
+		fb := m.Fallback
+		if fb == nil {
+			fb = DefaultFallback
+		}
+		return fb(ctx, req, err), nil
+	}
+	return dff, err
+}
+
+// ServeHTTP implements the http.Handler interface. Requests coming from an
+// unknown agent are answered with a 404 status code.
+func (m *AgentMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serve(w, r, m.DecodeOptions, m.ErrorLog, m.Handle)
+}
@@ -0,0 +1,114 @@
+package dialogflow
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgentMux_Handle(t *testing.T) {
+	m := NewAgentMux()
+	m.HandleAgent("agent-fr", textHandler("fr"))
+	m.HandleAgent("agent-es", textHandler("es"))
+	m.HandleAgentEnvironment("agent-fr", "staging", textHandler("fr staging"))
+
+	tests := []struct {
+		name    string
+		session string
+		want    string
+		wantErr bool
+	}{
+		{"should route by project", "projects/agent-fr/agent/sessions/1234", "fr", false},
+		{"should route other project", "projects/agent-es/agent/sessions/1234", "es", false},
+		{"should route by environment", "projects/agent-fr/agent/environments/staging/users/u/sessions/1234", "fr staging", false},
+		{"should fall back to project", "projects/agent-es/agent/environments/staging/users/u/sessions/1234", "es", false},
+		{"should fail on unknown project", "projects/agent-de/agent/sessions/1234", "", true},
+		{"should fail on malformed session", "agent-fr", "", true},
+		{"should fail on empty session", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.Handle(context.Background(), &Request{Session: tt.session})
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrUnknownAgent), "expected ErrUnknownAgent, got %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.FulfillmentText)
+		})
+	}
+}
+
+func TestAgentMux_ServeHTTP(t *testing.T) {
+	fr := NewRouter()
+	fr.HandleIntent("greeting", textHandler("bonjour"))
+	m := NewAgentMux()
+	m.HandleAgent("agent-fr", fr.Handle)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		want   string
+	}{
+		{
+			"should route to the agent router",
+			`{"session": "projects/agent-fr/agent/sessions/1234", "queryResult": {"intent": {"displayName": "greeting"}}}`,
+			http.StatusOK,
+			`{"fulfillmentText": "bonjour", "followupEventInput": {"name": ""}}`,
+		},
+		{
+			"should not find the intent",
+			`{"session": "projects/agent-fr/agent/sessions/1234", "queryResult": {"intent": {"displayName": "unknown"}}}`,
+			http.StatusNotFound,
+			"dialogflow: no handler registered\n",
+		},
+		{
+			"should not find the agent",
+			`{"session": "projects/agent-de/agent/sessions/1234", "queryResult": {}}`,
+			http.StatusNotFound,
+			"dialogflow: unknown agent: project \"agent-de\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(tt.body)))
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status != http.StatusOK {
+				assert.Equal(t, tt.want, w.Body.String())
+				return
+			}
+			if err := JSONStringsEqual(w.Body.String(), tt.want); err != nil {
+				t.Errorf("AgentMux.ServeHTTP() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestAgentMux_Fallback(t *testing.T) {
+	m := NewAgentMux()
+	m.HandleAgent("broken", func(ctx context.Context, req *Request) (*Fulfillment, error) {
+		return nil, errors.New("broken")
+	})
+	m.HandleAgent("router", NewRouter().Handle)
+
+	got, err := m.Handle(context.Background(), &Request{Session: "projects/broken/agent/sessions/1234"})
+	assert.NoError(t, err)
+	assert.Equal(t, &Fulfillment{}, got, "should use the default fallback")
+
+	m.Fallback = FallbackFulfillment(&Fulfillment{FulfillmentText: "sorry"})
+	rec := httptest.NewRecorder()
+	body := strings.NewReader(`{"session": "projects/broken/agent/sessions/1234", "queryResult": {}}`)
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhook", body))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "sorry")
+
+	_, err = m.Handle(context.Background(), &Request{Session: "projects/router/agent/sessions/1234"})
+	assert.Equal(t, ErrNoHandler, err, "should not hide ErrNoHandler")
+}
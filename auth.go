@@ -0,0 +1,89 @@
+package dialogflow
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+)
+
+// Authenticator checks whether an incoming HTTP request is allowed to reach
+// the webhook
+type Authenticator func(r *http.Request) bool
+
+// Credentials holds a username and a password used for HTTP basic auth
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// BasicAuth returns an Authenticator accepting requests whose basic auth
+// credentials match any of the given ones. Several credentials can be given
+// to rotate them without downtime. Credentials with an empty password are
+// ignored, so that an unset environment variable can't open the webhook.
+func BasicAuth(creds ...Credentials) Authenticator {
+	var valid []Credentials
+	for _, c := range creds {
+		if c.Password != "" {
+			valid = append(valid, c)
+		}
+	}
+	return func(r *http.Request) bool {
+		u, p, ok := r.BasicAuth()
+		if !ok {
+			return false
+		}
+		match := 0
+		for _, c := range valid {
+			match |= secureCompare(u, c.Username) & secureCompare(p, c.Password)
+		}
+		return match == 1
+	}
+}
+
+// HeaderSecret returns an Authenticator accepting requests whose header
+// matches any of the given secrets. Several secrets can be given to rotate
+// them without downtime. Empty secrets are ignored, so that an unset
+// environment variable can't open the webhook.
+func HeaderSecret(header string, secrets ...string) Authenticator {
+	var valid []string
+	for _, s := range secrets {
+		if s != "" {
+			valid = append(valid, s)
+		}
+	}
+	return func(r *http.Request) bool {
+		values, ok := r.Header[http.CanonicalHeaderKey(header)]
+		if !ok || len(values) != 1 {
+			return false
+		}
+		match := 0
+		for _, s := range valid {
+			match |= secureCompare(values[0], s)
+		}
+		return match == 1
+	}
+}
+
+// Authenticate returns an http.Handler that only calls next if all the
+// authenticators accept the request, which happens before the request body
+// is even read. Other requests are answered with a 401 status code.
+func Authenticate(next http.Handler, auths ...Authenticator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, a := range auths {
+			if !a(r) {
+				w.Header().Set("WWW-Authenticate", `Basic realm="dialogflow"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// secureCompare compares two strings in constant time, and returns 1 if they
+// are equal, 0 otherwise. Both strings are hashed first so that the
+// comparison doesn't leak their length.
+func secureCompare(a, b string) int {
+	ha, hb := sha256.Sum256([]byte(a)), sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(ha[:], hb[:])
+}
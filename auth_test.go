@@ -0,0 +1,101 @@
+package dialogflow
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicAuth(t *testing.T) {
+	a := BasicAuth(Credentials{"user", "old"}, Credentials{"user", "new"}, Credentials{"nopass", ""})
+	tests := []struct {
+		name     string
+		setAuth  bool
+		user     string
+		password string
+		want     bool
+	}{
+		{"should accept old credentials", true, "user", "old", true},
+		{"should accept new credentials", true, "user", "new", true},
+		{"should refuse wrong password", true, "user", "wrong", false},
+		{"should refuse wrong user", true, "other", "new", false},
+		{"should refuse mixed credentials", true, "user", "oldnew", false},
+		{"should refuse missing credentials", false, "", "", false},
+		{"should ignore credentials without password", true, "nopass", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+			if tt.setAuth {
+				r.SetBasicAuth(tt.user, tt.password)
+			}
+			assert.Equal(t, tt.want, a(r))
+		})
+	}
+}
+
+func TestHeaderSecret(t *testing.T) {
+	a := HeaderSecret("X-Webhook-Secret", "old", "new", "")
+	tests := []struct {
+		name   string
+		values []string
+		want   bool
+	}{
+		{"should accept old secret", []string{"old"}, true},
+		{"should accept new secret", []string{"new"}, true},
+		{"should refuse wrong secret", []string{"wrong"}, false},
+		{"should refuse empty secret", []string{""}, false},
+		{"should refuse multiple values", []string{"old", "new"}, false},
+		{"should refuse missing header", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+			for _, v := range tt.values {
+				r.Header.Add("x-webhook-secret", v)
+			}
+			assert.Equal(t, tt.want, a(r))
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	h := Authenticate(next, BasicAuth(Credentials{"user", "password"}), HeaderSecret("X-Webhook-Secret", "secret"))
+
+	tests := []struct {
+		name   string
+		basic  bool
+		secret string
+		status int
+	}{
+		{"should accept", true, "secret", http.StatusOK},
+		{"should refuse without basic auth", false, "secret", http.StatusUnauthorized},
+		{"should refuse without secret", true, "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+			if tt.basic {
+				r.SetBasicAuth("user", "password")
+			}
+			if tt.secret != "" {
+				r.Header.Set("X-Webhook-Secret", tt.secret)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestHeaderSecret_Empty(t *testing.T) {
+	a := HeaderSecret("X-Webhook-Secret", "")
+	r := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+	r.Header.Set("X-Webhook-Secret", "")
+	assert.False(t, a(r), "should refuse everything when no secret is configured")
+}
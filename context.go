@@ -1,13 +1,83 @@
 package dialogflow
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrContextNotFound is returned when looking for a context that isn't
+// present in the request
+var ErrContextNotFound = errors.New("context not found")
 
 // Context is a context contained in a query
 type Context struct {
 	Name          string          `json:"name,omitempty"`
-	LifespanCount int             `json:"lifespanCount,omitempty"`
+	LifespanCount int             `json:"lifespanCount"` // Always sent, since 0 deletes the context
 	Parameters    json.RawMessage `json:"parameters,omitempty"`
 }
 
+// ShortName returns the name of the context without the session part, or an
+// empty string if the context is nil
+func (c *Context) ShortName() string {
+	if c == nil {
+		return ""
+	}
+	return ParseContextName(c.Name).Name
+}
+
+// isSystemContext reports whether the context is managed by dialogflow or an
+// integration, such as __system_counters__ or actions_capability_*, rather
+// than by the agent
+func isSystemContext(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "__system") || strings.HasPrefix(name, "actions_capability_")
+}
+
 // Contexts is a slice of pointer to Context
 type Contexts []*Context
+
+// Get returns the context whose short name is the given name. Names are
+// compared case insensitively, since dialogflow lowercases them, and nil
+// contexts are skipped. ErrContextNotFound is returned if there is none.
+func (cs Contexts) Get(name string) (*Context, error) {
+	for _, c := range cs {
+		if c != nil && strings.EqualFold(c.ShortName(), name) {
+			return c, nil
+		}
+	}
+	return nil, ErrContextNotFound
+}
+
+// ContextName is the parsed form of a context name, which is
+// <session>/contexts/<name>
+type ContextName struct {
+	Session string // Empty if the context name is a short name
+	Name    string
+}
+
+// ParseContextName parses a full context name. Short names, which don't
+// contain the session, are also accepted.
+func ParseContextName(s string) ContextName {
+	if i := strings.LastIndex(s, "/contexts/"); i >= 0 {
+		return ContextName{Session: s[:i], Name: s[i+len("/contexts/"):]}
+	}
+	return ContextName{Name: s}
+}
+
+// String returns the full context name, or the short name if the session is
+// empty
+func (c ContextName) String() string {
+	if c.Session == "" {
+		return c.Name
+	}
+	return fmt.Sprintf("%s/contexts/%s", c.Session, c.Name)
+}
+
+// ActiveContext describes a context of the request and its remaining
+// lifespan
+type ActiveContext struct {
+	Name          ContextName
+	LifespanCount int
+}
@@ -0,0 +1,80 @@
+package dialogflow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseContextName(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want ContextName
+	}{
+		{"should parse full name", "projects/p/agent/sessions/s/contexts/order", ContextName{"projects/p/agent/sessions/s", "order"}},
+		{
+			"should parse environment session",
+			"projects/p/agent/environments/e/users/u/sessions/s/contexts/order",
+			ContextName{"projects/p/agent/environments/e/users/u/sessions/s", "order"},
+		},
+		{"should parse short name", "order", ContextName{Name: "order"}},
+		{"should parse empty name", "", ContextName{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseContextName(tt.in)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.in, got.String(), "should format back to the same name")
+		})
+	}
+}
+
+func TestContext_ShortName(t *testing.T) {
+	assert.Equal(t, "order", (&Context{Name: "projects/p/agent/sessions/s/contexts/order"}).ShortName())
+	assert.Equal(t, "order", (&Context{Name: "order"}).ShortName())
+}
+
+func TestContexts_Get(t *testing.T) {
+	order := &Context{Name: "projects/p/agent/sessions/s/contexts/order"}
+	cs := Contexts{nil, {Name: "projects/p/agent/sessions/s/contexts/pre-order"}, order}
+
+	got, err := cs.Get("order")
+	assert.NoError(t, err)
+	assert.Equal(t, order, got)
+	got, err = cs.Get("Order")
+	assert.NoError(t, err, "should ignore case")
+	assert.Equal(t, order, got)
+	_, err = cs.Get("")
+	assert.Equal(t, ErrContextNotFound, err, "should skip nil contexts")
+
+	_, err = cs.Get("der")
+	assert.Equal(t, ErrContextNotFound, err)
+	_, err = Contexts{}.Get("order")
+	assert.Equal(t, ErrContextNotFound, err)
+}
+
+func TestRequest_NilContexts(t *testing.T) {
+	var rw Request
+	body := `{"session": "s", "queryResult": {"parameters": {"size": "large"}, "outputContexts": [null, {"name": "s/contexts/order", "lifespanCount": 2, "parameters": {"size": "small"}}]}}`
+	if !assert.NoError(t, json.Unmarshal([]byte(body), &rw)) {
+		return
+	}
+
+	var p struct {
+		Size string `json:"size"`
+	}
+	assert.NoError(t, rw.GetContext("Order", &p))
+	assert.Equal(t, "small", p.Size)
+	assert.Equal(t, "large", rw.Params().String("size"))
+	assert.Len(t, rw.ActiveContexts(), 1)
+	assert.False(t, rw.HasScreen())
+	assert.NotPanics(t, func() { rw.SlotFilling() })
+
+	m := NewContextManager(&rw)
+	assert.NoError(t, m.Extend("order", 1))
+	f := &Fulfillment{OutputContexts: Contexts{nil}}
+	m.Apply(f)
+	assert.Len(t, f.OutputContexts, 1)
+}
@@ -0,0 +1,111 @@
+package dialogflow
+
+import "strings"
+
+// ContextManager builds the output contexts of a fulfillment from the
+// contexts of the incoming request. Each context name can only appear once
+// in the output, the last operation on a name wins.
+type ContextManager struct {
+	req *Request
+	out Contexts
+}
+
+// NewContextManager returns a new ContextManager for the given request
+func NewContextManager(req *Request) *ContextManager {
+	return &ContextManager{req: req}
+}
+
+// Set sets the context with the given name, lifespan and parameters
+func (m *ContextManager) Set(name string, lifespan int, params interface{}) error {
+	c, err := m.req.NewContext(name, lifespan, params)
+	if err != nil {
+		return err
+	}
+	m.put(c)
+	return nil
+}
+
+// Delete deletes the context by sending it with a lifespan of 0
+func (m *ContextManager) Delete(name string) {
+	m.put(&Context{Name: m.name(name)})
+}
+
+// Extend keeps the context alive for n more turns, with its current
+// parameters. The context is searched in the contexts already set in the
+// manager first, then in the incoming request. ErrContextNotFound is
+// returned if there is none.
+func (m *ContextManager) Extend(name string, n int) error {
+	c, err := m.current(name)
+	if err != nil {
+		return err
+	}
+	m.put(&Context{Name: m.name(name), LifespanCount: c.LifespanCount + n, Parameters: c.Parameters})
+	return nil
+}
+
+// Carry keeps the incoming context alive as is, with its current lifespan and
+// parameters. ErrContextNotFound is returned if there is no such context in
+// the request.
+func (m *ContextManager) Carry(name string) error {
+	c, err := m.req.QueryResult.OutputContexts.Get(name)
+	if err != nil {
+		return err
+	}
+	m.put(&Context{Name: m.name(name), LifespanCount: c.LifespanCount, Parameters: c.Parameters})
+	return nil
+}
+
+// Contexts returns the output contexts built so far
+func (m *ContextManager) Contexts() Contexts {
+	return m.out
+}
+
+// Apply writes the output contexts to the fulfillment. Contexts of the
+// fulfillment having the same name as a context of the manager are replaced.
+func (m *ContextManager) Apply(f *Fulfillment) {
+	for _, c := range m.out {
+		var out Contexts
+		replaced := false
+		for _, oc := range f.OutputContexts {
+			if oc == nil {
+				continue
+			}
+			if !strings.EqualFold(oc.ShortName(), c.ShortName()) {
+				out = append(out, oc)
+			} else if !replaced {
+				out = append(out, c)
+				replaced = true
+			}
+		}
+		if !replaced {
+			out = append(out, c)
+		}
+		f.OutputContexts = out
+	}
+}
+
+// current returns the context as set in the manager, or as found in the
+// request
+func (m *ContextManager) current(name string) (*Context, error) {
+	if c, err := m.out.Get(name); err == nil {
+		return c, nil
+	}
+	return m.req.QueryResult.OutputContexts.Get(name)
+}
+
+// name returns the full name of the context in the session of the request
+func (m *ContextManager) name(name string) string {
+	return ContextName{Session: m.req.Session, Name: name}.String()
+}
+
+// put adds the context to the output, replacing any context with the same
+// name
+func (m *ContextManager) put(c *Context) {
+	for i, oc := range m.out {
+		if strings.EqualFold(oc.Name, c.Name) {
+			m.out[i] = c
+			return
+		}
+	}
+	m.out = append(m.out, c)
+}
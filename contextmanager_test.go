@@ -0,0 +1,67 @@
+package dialogflow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func managerRequest() *Request {
+	return &Request{
+		Session: "projects/p/agent/sessions/s",
+		QueryResult: QueryResult{OutputContexts: Contexts{
+			{"projects/p/agent/sessions/s/contexts/order", 2, json.RawMessage(`{"size":"large"}`)},
+			{"projects/p/agent/sessions/s/contexts/cart", 1, json.RawMessage(`{"items":2}`)},
+		}},
+	}
+}
+
+func TestContextManager(t *testing.T) {
+	m := NewContextManager(managerRequest())
+
+	assert.NoError(t, m.Set("greeting", 3, map[string]string{"name": "John"}))
+	assert.Error(t, m.Set("wrong", 3, make(chan int)))
+	m.Delete("cart")
+	assert.NoError(t, m.Extend("order", 2))
+	assert.NoError(t, m.Extend("order", 1), "should extend the already extended context")
+	assert.NoError(t, m.Carry("cart"), "should replace the deletion")
+	assert.Equal(t, ErrContextNotFound, m.Extend("unknown", 1))
+	assert.Equal(t, ErrContextNotFound, m.Carry("unknown"))
+
+	want := Contexts{
+		{"projects/p/agent/sessions/s/contexts/greeting", 3, json.RawMessage(`{"name":"John"}`)},
+		{"projects/p/agent/sessions/s/contexts/cart", 1, json.RawMessage(`{"items":2}`)},
+		{"projects/p/agent/sessions/s/contexts/order", 5, json.RawMessage(`{"size":"large"}`)},
+	}
+	assert.Equal(t, want, m.Contexts())
+}
+
+func TestContextManager_Delete(t *testing.T) {
+	m := NewContextManager(managerRequest())
+	m.Delete("order")
+	f := &Fulfillment{}
+	m.Apply(f)
+	if err := PayloadTester(f.OutputContexts, []byte(`[{"name": "projects/p/agent/sessions/s/contexts/order", "lifespanCount": 0}]`)); err != nil {
+		t.Errorf("ContextManager.Delete() error = %v", err)
+	}
+}
+
+func TestContextManager_Apply(t *testing.T) {
+	m := NewContextManager(managerRequest())
+	assert.NoError(t, m.Carry("order"))
+	assert.NoError(t, m.Set("greeting", 1, nil))
+
+	f := &Fulfillment{OutputContexts: Contexts{
+		{"projects/p/agent/sessions/s/contexts/other", 1, nil},
+		{"projects/p/agent/sessions/s/contexts/order", 10, nil},
+		{"projects/p/agent/sessions/s/contexts/order", 11, nil},
+	}}
+	m.Apply(f)
+	want := Contexts{
+		{"projects/p/agent/sessions/s/contexts/other", 1, nil},
+		{"projects/p/agent/sessions/s/contexts/order", 2, json.RawMessage(`{"size":"large"}`)},
+		{"projects/p/agent/sessions/s/contexts/greeting", 1, json.RawMessage(`null`)},
+	}
+	assert.Equal(t, want, f.OutputContexts)
+}
@@ -0,0 +1,115 @@
+// Package cx provides the types and helpers to write Dialogflow CX webhooks.
+// Handlers are dispatched using the tag of the fulfillment that called the
+// webhook, and answer with a Response that can send messages, update the
+// session parameters and transition to another page or flow.
+package cx
+
+import (
+	"encoding/json"
+
+	df "github.com/leboncoin/dialogflow-go-webhook"
+)
+
+// Request is the webhook request sent by Dialogflow CX
+// https://cloud.google.com/dialogflow/cx/docs/reference/rest/v3/WebhookRequest
+type Request struct {
+	DetectIntentResponseID  string            `json:"detectIntentResponseId,omitempty"`
+	Text                    string            `json:"text,omitempty"`
+	TriggerIntent           string            `json:"triggerIntent,omitempty"`
+	Transcript              string            `json:"transcript,omitempty"`
+	TriggerEvent            string            `json:"triggerEvent,omitempty"`
+	LanguageCode            string            `json:"languageCode,omitempty"`
+	FulfillmentInfo         FulfillmentInfo   `json:"fulfillmentInfo,omitempty"`
+	IntentInfo              *IntentInfo       `json:"intentInfo,omitempty"`
+	PageInfo                *PageInfo         `json:"pageInfo,omitempty"`
+	SessionInfo             SessionInfo       `json:"sessionInfo,omitempty"`
+	Messages                []ResponseMessage `json:"messages,omitempty"`
+	Payload                 json.RawMessage   `json:"payload,omitempty"`
+	SentimentAnalysisResult *df.Sentiment     `json:"sentimentAnalysisResult,omitempty"`
+}
+
+// FulfillmentInfo holds the tag of the fulfillment that called the webhook
+type FulfillmentInfo struct {
+	Tag string `json:"tag,omitempty"`
+}
+
+// IntentInfo describes the intent matched by the query
+type IntentInfo struct {
+	LastMatchedIntent string                          `json:"lastMatchedIntent,omitempty"` // projects/<project>/locations/<location>/agents/<agent>/intents/<id>
+	DisplayName       string                          `json:"displayName,omitempty"`
+	Parameters        map[string]IntentParameterValue `json:"parameters,omitempty"`
+	Confidence        float64                         `json:"confidence,omitempty"`
+}
+
+// IntentParameterValue is the value of a parameter of the matched intent
+type IntentParameterValue struct {
+	OriginalValue string          `json:"originalValue,omitempty"`
+	ResolvedValue json.RawMessage `json:"resolvedValue,omitempty"`
+}
+
+// PageInfo describes the current page. It can be sent back in the Response to
+// change the state of the form parameters.
+type PageInfo struct {
+	CurrentPage string    `json:"currentPage,omitempty"` // projects/<project>/locations/<location>/agents/<agent>/flows/<flow>/pages/<page>
+	DisplayName string    `json:"displayName,omitempty"`
+	FormInfo    *FormInfo `json:"formInfo,omitempty"`
+}
+
+// FormInfo holds the state of the parameters of the page's form
+type FormInfo struct {
+	ParameterInfo []ParameterInfo `json:"parameterInfo,omitempty"`
+}
+
+// Parameter states, see ParameterInfo
+const (
+	ParameterEmpty   = "EMPTY"
+	ParameterInvalid = "INVALID"
+	ParameterFilled  = "FILLED"
+)
+
+// ParameterInfo is the state of a form parameter
+type ParameterInfo struct {
+	DisplayName   string          `json:"displayName,omitempty"`
+	Required      bool            `json:"required,omitempty"`
+	State         string          `json:"state,omitempty"` // One of the Parameter* constants
+	Value         json.RawMessage `json:"value,omitempty"`
+	JustCollected bool            `json:"justCollected,omitempty"`
+}
+
+// Parameter returns the form parameter with the given display name
+func (f *FormInfo) Parameter(name string) (ParameterInfo, bool) {
+	for _, p := range f.ParameterInfo {
+		if p.DisplayName == name {
+			return p, true
+		}
+	}
+	return ParameterInfo{}, false
+}
+
+// SessionInfo holds the session and its parameters. In a Response, setting a
+// parameter to nil removes it from the session.
+type SessionInfo struct {
+	Session    string                 `json:"session,omitempty"` // projects/<project>/locations/<location>/agents/<agent>/sessions/<session>
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// Tag returns the tag of the fulfillment that called the webhook
+func (r *Request) Tag() string {
+	return r.FulfillmentInfo.Tag
+}
+
+// GetParams unmarshals the session parameters to the given struct and returns
+// an error if it's not possible
+func (r *Request) GetParams(i interface{}) error {
+	b, err := json.Marshal(r.SessionInfo.Parameters)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, i)
+}
+
+// Param returns the raw value of a session parameter
+func (r *Request) Param(name string) (interface{}, bool) {
+	v, ok := r.SessionInfo.Parameters[name]
+	return v, ok
+}
@@ -0,0 +1,86 @@
+package cx
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const cxRequest = `{
+	"detectIntentResponseId": "r",
+	"languageCode": "en",
+	"text": "I want a large pizza",
+	"fulfillmentInfo": {"tag": "order.pizza"},
+	"intentInfo": {
+		"lastMatchedIntent": "projects/p/locations/global/agents/a/intents/1",
+		"displayName": "order",
+		"parameters": {"size": {"originalValue": "large", "resolvedValue": "LARGE"}},
+		"confidence": 0.9
+	},
+	"pageInfo": {
+		"currentPage": "projects/p/locations/global/agents/a/flows/f/pages/order",
+		"formInfo": {"parameterInfo": [
+			{"displayName": "size", "required": true, "state": "FILLED", "value": "LARGE", "justCollected": true},
+			{"displayName": "topping", "required": true, "state": "EMPTY"}
+		]}
+	},
+	"sessionInfo": {
+		"session": "projects/p/locations/global/agents/a/sessions/s",
+		"parameters": {"size": "LARGE", "count": 2}
+	},
+	"sentimentAnalysisResult": {"score": -0.5, "magnitude": 1.2}
+}`
+
+func TestRequest_Unmarshal(t *testing.T) {
+	var req Request
+	assert.NoError(t, json.Unmarshal([]byte(cxRequest), &req))
+	assert.Equal(t, "order.pizza", req.Tag())
+	assert.Equal(t, "order", req.IntentInfo.DisplayName)
+	assert.Equal(t, "large", req.IntentInfo.Parameters["size"].OriginalValue)
+	assert.Equal(t, "projects/p/locations/global/agents/a/sessions/s", req.SessionInfo.Session)
+	assert.Equal(t, -0.5, req.SentimentAnalysisResult.Score)
+	assert.Len(t, req.PageInfo.FormInfo.ParameterInfo, 2)
+}
+
+func TestRequest_GetParams(t *testing.T) {
+	var req Request
+	assert.NoError(t, json.Unmarshal([]byte(cxRequest), &req))
+
+	var params struct {
+		Size  string `json:"size"`
+		Count int    `json:"count"`
+	}
+	assert.NoError(t, req.GetParams(&params))
+	assert.Equal(t, "LARGE", params.Size)
+	assert.Equal(t, 2, params.Count)
+
+	v, ok := req.Param("count")
+	assert.True(t, ok)
+	assert.Equal(t, float64(2), v)
+	_, ok = req.Param("unknown")
+	assert.False(t, ok)
+}
+
+func TestFormInfo_Parameter(t *testing.T) {
+	var req Request
+	assert.NoError(t, json.Unmarshal([]byte(cxRequest), &req))
+
+	tests := []struct {
+		name      string
+		param     string
+		wantOk    bool
+		wantState string
+	}{
+		{"should find filled parameter", "size", true, ParameterFilled},
+		{"should find empty parameter", "topping", true, ParameterEmpty},
+		{"should not find unknown parameter", "crust", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := req.PageInfo.FormInfo.Parameter(tt.param)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.wantState, p.State)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package cx
+
+import (
+	df "github.com/leboncoin/dialogflow-go-webhook"
+)
+
+// Merge behaviours of the fulfillment messages, see FulfillmentResponse
+const (
+	MergeAppend  = "APPEND"
+	MergeReplace = "REPLACE"
+)
+
+// Response is the webhook response sent back to Dialogflow CX
+// https://cloud.google.com/dialogflow/cx/docs/reference/rest/v3/WebhookResponse
+type Response struct {
+	FulfillmentResponse *FulfillmentResponse `json:"fulfillmentResponse,omitempty"`
+	PageInfo            *PageInfo            `json:"pageInfo,omitempty"`
+	SessionInfo         *SessionInfo         `json:"sessionInfo,omitempty"`
+	Payload             interface{}          `json:"payload,omitempty"`
+	TargetPage          string               `json:"targetPage,omitempty"` // projects/<project>/locations/<location>/agents/<agent>/flows/<flow>/pages/<page>
+	TargetFlow          string               `json:"targetFlow,omitempty"` // projects/<project>/locations/<location>/agents/<agent>/flows/<flow>
+}
+
+// FulfillmentResponse holds the messages sent to the user
+type FulfillmentResponse struct {
+	Messages      []ResponseMessage `json:"messages,omitempty"`
+	MergeBehavior string            `json:"mergeBehavior,omitempty"` // One of the Merge* constants
+}
+
+// ResponseMessage is a message sent to the user. Only one of its fields
+// should be set.
+type ResponseMessage struct {
+	Text                *Text                `json:"text,omitempty"`
+	Payload             interface{}          `json:"payload,omitempty"`
+	ConversationSuccess *ConversationSuccess `json:"conversationSuccess,omitempty"`
+	OutputAudioText     *OutputAudioText     `json:"outputAudioText,omitempty"`
+	LiveAgentHandoff    *LiveAgentHandoff    `json:"liveAgentHandoff,omitempty"`
+	EndInteraction      *EndInteraction      `json:"endInteraction,omitempty"`
+	PlayAudio           *PlayAudio           `json:"playAudio,omitempty"`
+	Channel             string               `json:"channel,omitempty"`
+}
+
+// Text is a text message, one of the texts is picked randomly
+type Text struct {
+	Text                      []string `json:"text,omitempty"`
+	AllowPlaybackInterruption bool     `json:"allowPlaybackInterruption,omitempty"`
+}
+
+// ConversationSuccess indicates the conversation succeeded
+type ConversationSuccess struct {
+	Metadata interface{} `json:"metadata,omitempty"`
+}
+
+// OutputAudioText is a text or SSML message synthesized to audio
+type OutputAudioText struct {
+	Text string `json:"text,omitempty"`
+	SSML string `json:"ssml,omitempty"`
+}
+
+// LiveAgentHandoff requests the conversation to be handed off to a human
+type LiveAgentHandoff struct {
+	Metadata interface{} `json:"metadata,omitempty"`
+}
+
+// EndInteraction indicates the interaction ended. It is only found in
+// requests.
+type EndInteraction struct{}
+
+// PlayAudio plays an audio file, on telephony integrations only
+type PlayAudio struct {
+	AudioURI                  string `json:"audioUri,omitempty"`
+	AllowPlaybackInterruption bool   `json:"allowPlaybackInterruption,omitempty"`
+}
+
+// NewResponse returns a new empty response
+func NewResponse() *Response {
+	return &Response{}
+}
+
+// AddMessage appends messages to the fulfillment response
+func (r *Response) AddMessage(m ...ResponseMessage) *Response {
+	if r.FulfillmentResponse == nil {
+		r.FulfillmentResponse = &FulfillmentResponse{}
+	}
+	r.FulfillmentResponse.Messages = append(r.FulfillmentResponse.Messages, m...)
+	return r
+}
+
+// AddText appends a text message to the fulfillment response. When several
+// texts are given, one of them is picked randomly by Dialogflow.
+func (r *Response) AddText(text ...string) *Response {
+	return r.AddMessage(ResponseMessage{Text: &Text{Text: text}})
+}
+
+// AddPayload appends a custom payload message to the fulfillment response
+func (r *Response) AddPayload(payload interface{}) *Response {
+	return r.AddMessage(ResponseMessage{Payload: payload})
+}
+
+// SetMergeBehavior sets how the messages are merged with the ones defined in
+// the agent, see the Merge* constants
+func (r *Response) SetMergeBehavior(b string) *Response {
+	if r.FulfillmentResponse == nil {
+		r.FulfillmentResponse = &FulfillmentResponse{}
+	}
+	r.FulfillmentResponse.MergeBehavior = b
+	return r
+}
+
+// SetParameter sets a session parameter. Setting a parameter to nil removes
+// it from the session.
+func (r *Response) SetParameter(name string, value interface{}) *Response {
+	if r.SessionInfo == nil {
+		r.SessionInfo = &SessionInfo{}
+	}
+	if r.SessionInfo.Parameters == nil {
+		r.SessionInfo.Parameters = make(map[string]interface{})
+	}
+	r.SessionInfo.Parameters[name] = value
+	return r
+}
+
+// DeleteParameter removes a parameter from the session
+func (r *Response) DeleteParameter(name string) *Response {
+	return r.SetParameter(name, nil)
+}
+
+// SetTargetPage transitions the session to the given page, using its full
+// name. It replaces any target flow, since only one of them can be set.
+func (r *Response) SetTargetPage(page string) *Response {
+	r.TargetPage = page
+	r.TargetFlow = ""
+	return r
+}
+
+// SetTargetFlow transitions the session to the given flow, using its full
+// name. It replaces any target page, since only one of them can be set.
+func (r *Response) SetTargetFlow(flow string) *Response {
+	r.TargetFlow = flow
+	r.TargetPage = ""
+	return r
+}
+
+// FromFulfillment converts a Dialogflow ES fulfillment to a Response, so that
+// the same logic can answer both ES and CX webhooks. Text messages and custom
+// payloads without a platform are kept, other rich messages are ignored since
+// CX has no equivalent. The fulfillment text is used when no message was
+// kept.
+func FromFulfillment(f *df.Fulfillment) *Response {
+	r := NewResponse()
+	if f == nil {
+		return r
+	}
+	for _, m := range f.FulfillmentMessages {
+		if m.Platform != "" && m.Platform != df.Unspecified {
+			continue
+		}
+		switch rm := m.RichMessage.(type) {
+		case df.Text:
+			r.AddText(rm.Text...)
+		case *df.Text:
+			r.AddText(rm.Text...)
+		case df.PayloadWrapper:
+			r.AddPayload(rm.Payload)
+		case *df.PayloadWrapper:
+			r.AddPayload(rm.Payload)
+		}
+	}
+	if r.FulfillmentResponse == nil && f.FulfillmentText != "" {
+		r.AddText(f.FulfillmentText)
+	}
+	r.Payload = f.Payload
+	return r
+}
@@ -0,0 +1,85 @@
+package cx
+
+import (
+	"encoding/json"
+	"testing"
+
+	df "github.com/leboncoin/dialogflow-go-webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponse_Builder(t *testing.T) {
+	res := NewResponse().
+		AddText("Which topping?").
+		AddPayload(map[string]string{"kind": "chips"}).
+		SetMergeBehavior(MergeReplace).
+		SetParameter("size", "LARGE").
+		DeleteParameter("topping").
+		SetTargetFlow("projects/p/locations/global/agents/a/flows/f").
+		SetTargetPage("projects/p/locations/global/agents/a/flows/f/pages/topping")
+
+	b, err := json.Marshal(res)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"fulfillmentResponse": {
+			"messages": [{"text": {"text": ["Which topping?"]}}, {"payload": {"kind": "chips"}}],
+			"mergeBehavior": "REPLACE"
+		},
+		"sessionInfo": {"parameters": {"size": "LARGE", "topping": null}},
+		"targetPage": "projects/p/locations/global/agents/a/flows/f/pages/topping"
+	}`, string(b))
+}
+
+func TestResponse_SetTargetFlow(t *testing.T) {
+	res := NewResponse().SetTargetPage("page").SetTargetFlow("flow")
+	assert.Equal(t, "flow", res.TargetFlow)
+	assert.Empty(t, res.TargetPage)
+}
+
+func TestFromFulfillment(t *testing.T) {
+	tests := []struct {
+		name string
+		f    *df.Fulfillment
+		want *Response
+	}{
+		{"should handle nil fulfillment", nil, &Response{}},
+		{"should handle empty fulfillment", &df.Fulfillment{}, &Response{}},
+		{
+			"should convert fulfillment text",
+			&df.Fulfillment{FulfillmentText: "hello"},
+			NewResponse().AddText("hello"),
+		},
+		{
+			"should convert messages and ignore the text",
+			&df.Fulfillment{
+				FulfillmentText: "ignored",
+				FulfillmentMessages: df.Messages{
+					{RichMessage: df.Text{Text: []string{"hi", "hello"}}},
+					{Platform: df.Unspecified, RichMessage: &df.PayloadWrapper{Payload: "custom"}},
+				},
+			},
+			NewResponse().AddText("hi", "hello").AddPayload("custom"),
+		},
+		{
+			"should ignore platform messages and unsupported types",
+			&df.Fulfillment{
+				FulfillmentText: "fallback",
+				FulfillmentMessages: df.Messages{
+					{Platform: df.ActionsOnGoogle, RichMessage: df.Text{Text: []string{"google"}}},
+					{RichMessage: df.Image{ImageURI: "https://example.com/image.png"}},
+				},
+			},
+			NewResponse().AddText("fallback"),
+		},
+		{
+			"should keep the payload",
+			&df.Fulfillment{Payload: map[string]bool{"custom": true}},
+			&Response{Payload: map[string]bool{"custom": true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FromFulfillment(tt.f))
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package cx
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	df "github.com/leboncoin/dialogflow-go-webhook"
+	"github.com/leboncoin/dialogflow-go-webhook/internal/webhook"
+)
+
+// HandlerFunc is the function signature used to handle a Dialogflow CX
+// request and build the response that will be sent back. The request is also
+// available in the context, see RequestFromContext.
+type HandlerFunc func(ctx context.Context, req *Request) (*Response, error)
+
+// ServeHTTP implements the http.Handler interface. The request body is
+// decoded to a Request, given to the handler, and the returned response is
+// encoded. If the handler returns an error, DefaultFallback is used to build
+// the response, see WithFallback to use another one.
+func (h HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	WithFallback(h, DefaultFallback).ServeHTTP(w, r)
+}
+
+// WithFallback returns an http.Handler serving the handler like
+// HandlerFunc.ServeHTTP, but using fb to build the response when the handler
+// returns an error
+func WithFallback(h HandlerFunc, fb FallbackFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serve(w, r, df.DecodeOptions{}, nil, withFallback(h, fb))
+	})
+}
+
+// withFallback wraps the handler so that the errors it returns are turned
+// into a response using fb
+func withFallback(h HandlerFunc, fb FallbackFunc) HandlerFunc {
+	return func(ctx context.Context, req *Request) (*Response, error) {
+		res, err := h(ctx, req)
+		if err != nil {
+			return fb(ctx, req, err), nil
+		}
+		return res, nil
+	}
+}
+
+// Middleware wraps a HandlerFunc to run code before or after it
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// FallbackFunc builds the response sent back to Dialogflow CX when a handler
+// returns an error
+type FallbackFunc func(ctx context.Context, req *Request, err error) *Response
+
+// DefaultFallback is the fallback used when none is configured. It returns an
+// empty response, which makes Dialogflow CX use the messages defined in the
+// fulfillment itself.
+func DefaultFallback(ctx context.Context, req *Request, err error) *Response {
+	return &Response{}
+}
+
+// contextKey is the type of the keys used to store values in a
+// context.Context, unexported to avoid collisions
+type contextKey int
+
+const requestKey contextKey = iota
+
+// WithRequest returns a copy of the context holding the given request
+func WithRequest(ctx context.Context, req *Request) context.Context {
+	return context.WithValue(ctx, requestKey, req)
+}
+
+// RequestFromContext returns the Dialogflow CX request stored in the context,
+// or nil if there is none
+func RequestFromContext(ctx context.Context) *Request {
+	req, _ := ctx.Value(requestKey).(*Request)
+	return req
+}
+
+// Router dispatches incoming Dialogflow CX requests to the handler registered
+// for the fulfillment tag, or to the default handler.
+// Router implements the http.Handler interface.
+type Router struct {
+	// Fallback builds the response sent back when a handler returns an
+	// error. DefaultFallback is used if nil.
+	Fallback FallbackFunc
+	// DecodeOptions are the options used to decode incoming requests
+	DecodeOptions df.DecodeOptions
+	// ErrorLog is an optional logger for requests that couldn't be decoded
+	ErrorLog *log.Logger
+
+	tags        map[string]HandlerFunc
+	def         HandlerFunc
+	middlewares []Middleware
+}
+
+// NewRouter returns a new empty router
+func NewRouter() *Router {
+	return &Router{tags: make(map[string]HandlerFunc)}
+}
+
+// HandleTag registers a handler for the given fulfillment tag
+func (r *Router) HandleTag(tag string, h HandlerFunc) {
+	r.tags[tag] = h
+}
+
+// Default registers the handler used when no handler is registered for the
+// tag of the incoming request
+func (r *Router) Default(h HandlerFunc) {
+	r.def = h
+}
+
+// Use appends middlewares to the router. They wrap every handler of the
+// router, the first one being the outermost.
+func (r *Router) Use(m ...Middleware) {
+	r.middlewares = append(r.middlewares, m...)
+}
+
+// Handle dispatches the request to the matching handler and returns the
+// resulting response. df.ErrNoHandler is returned if no handler matches.
+// Errors returned by the handler are turned into a response using the
+// router's Fallback.
+func (r *Router) Handle(ctx context.Context, req *Request) (*Response, error) {
+	ctx = WithRequest(ctx, req)
+	h, ok := r.tags[req.Tag()]
+	if !ok || req.Tag() == "" {
+		h = r.def
+	}
+	if h == nil {
+		return nil, df.ErrNoHandler
+	}
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		h = r.middlewares[i](h)
+	}
+	fb := r.Fallback
+	if fb == nil {
+		fb = DefaultFallback
+	}
+	return withFallback(h, fb)(ctx, req)
+}
+
+// ServeHTTP implements the http.Handler interface. The request body is
+// decoded to a Request using DecodeRequest, dispatched, and the returned
+// response is encoded.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	serve(w, req, r.DecodeOptions, r.ErrorLog, r.Handle)
+}
+
+// DecodeRequest reads and decodes the body of the HTTP request to a Request.
+// The sessionInfo field is required. A *df.DecodeError is returned if
+// anything goes wrong.
+func DecodeRequest(r *http.Request, opts df.DecodeOptions) (*Request, error) {
+	var req Request
+	if err := webhook.DecodeJSON(r, &req, opts, "sessionInfo"); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// serve decodes the incoming request using DecodeRequest, hands it to the
+// handler along with a context holding it, and encodes the returned response.
+// Requests that can't be decoded are logged using l if not nil.
+// df.ErrNoHandler is answered with a 404 status code, any other error with a
+// 500.
+func serve(w http.ResponseWriter, r *http.Request, opts df.DecodeOptions, l *log.Logger, h HandlerFunc) {
+	decode := func(r *http.Request) (interface{}, error) {
+		return DecodeRequest(r, opts)
+	}
+	handle := func(ctx context.Context, req interface{}) (interface{}, error) {
+		cxr := req.(*Request)
+		res, err := h(WithRequest(ctx, cxr), cxr)
+		if err == nil && res == nil {
+			res = &Response{}
+		}
+		return res, err
+	}
+	webhook.Serve(w, r, l, decode, handle, df.ErrNoHandler)
+}
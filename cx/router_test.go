@@ -0,0 +1,157 @@
+package cx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	df "github.com/leboncoin/dialogflow-go-webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func textHandler(text string) HandlerFunc {
+	return func(ctx context.Context, req *Request) (*Response, error) {
+		return NewResponse().AddText(text), nil
+	}
+}
+
+func firstText(res *Response) string {
+	if res == nil || res.FulfillmentResponse == nil || len(res.FulfillmentResponse.Messages) == 0 {
+		return ""
+	}
+	return res.FulfillmentResponse.Messages[0].Text.Text[0]
+}
+
+func TestRouter_Handle(t *testing.T) {
+	r := NewRouter()
+	r.HandleTag("order", textHandler("order"))
+	r.HandleTag("broken", func(ctx context.Context, req *Request) (*Response, error) {
+		return nil, errors.New("broken")
+	})
+
+	tests := []struct {
+		name     string
+		tag      string
+		def      HandlerFunc
+		fallback FallbackFunc
+		want     string
+		wantErr  error
+	}{
+		{"should match tag", "order", nil, nil, "order", nil},
+		{"should use default", "unknown", textHandler("default"), nil, "default", nil},
+		{"should use default without tag", "", textHandler("default"), nil, "default", nil},
+		{"should fail without default", "unknown", nil, nil, "", df.ErrNoHandler},
+		{"should use default fallback", "broken", nil, nil, "", nil},
+		{
+			"should use fallback",
+			"broken",
+			nil,
+			func(ctx context.Context, req *Request, err error) *Response {
+				return NewResponse().AddText(err.Error())
+			},
+			"broken",
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.Default(tt.def)
+			r.Fallback = tt.fallback
+			got, err := r.Handle(context.Background(), &Request{FulfillmentInfo: FulfillmentInfo{Tag: tt.tag}})
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr != nil {
+				return
+			}
+			assert.Equal(t, tt.want, firstText(got))
+		})
+	}
+}
+
+func TestRouter_Use(t *testing.T) {
+	var calls []string
+	mw := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(ctx context.Context, req *Request) (*Response, error) {
+				calls = append(calls, name)
+				assert.Equal(t, req, RequestFromContext(ctx))
+				return next(ctx, req)
+			}
+		}
+	}
+	r := NewRouter()
+	r.Use(mw("first"), mw("second"))
+	r.HandleTag("order", textHandler("order"))
+
+	got, err := r.Handle(context.Background(), &Request{FulfillmentInfo: FulfillmentInfo{Tag: "order"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "order", firstText(got))
+	assert.Equal(t, []string{"first", "second"}, calls)
+}
+
+func TestRouter_ServeHTTP(t *testing.T) {
+	r := NewRouter()
+	r.DecodeOptions.MaxBodySize = 200
+	r.HandleTag("order", func(ctx context.Context, req *Request) (*Response, error) {
+		return NewResponse().AddText("ok").SetParameter("size", "LARGE"), nil
+	})
+	r.HandleTag("empty", func(ctx context.Context, req *Request) (*Response, error) {
+		return nil, nil
+	})
+
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			"should respond",
+			http.MethodPost,
+			`{"fulfillmentInfo": {"tag": "order"}, "sessionInfo": {"session": "s"}}`,
+			http.StatusOK,
+			`{"fulfillmentResponse": {"messages": [{"text": {"text": ["ok"]}}]}, "sessionInfo": {"parameters": {"size": "LARGE"}}}`,
+		},
+		{"should respond to nil response", http.MethodPost, `{"fulfillmentInfo": {"tag": "empty"}, "sessionInfo": {}}`, http.StatusOK, `{}`},
+		{"should reject other methods", http.MethodGet, ``, http.StatusMethodNotAllowed, ""},
+		{"should reject missing session info", http.MethodPost, `{"fulfillmentInfo": {"tag": "order"}}`, http.StatusBadRequest, ""},
+		{"should reject large body", http.MethodPost, `{"sessionInfo": {"session": "` + strings.Repeat("s", 200) + `"}}`, http.StatusRequestEntityTooLarge, ""},
+		{"should not find unknown tag", http.MethodPost, `{"fulfillmentInfo": {"tag": "unknown"}, "sessionInfo": {}}`, http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(tt.method, "/webhook", strings.NewReader(tt.body)))
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerFunc_ServeHTTP(t *testing.T) {
+	h := HandlerFunc(func(ctx context.Context, req *Request) (*Response, error) {
+		return nil, errors.New("broken")
+	})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"sessionInfo": {"session": "s"}}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{}`, rec.Body.String())
+}
+
+func TestWithFallback(t *testing.T) {
+	h := func(ctx context.Context, req *Request) (*Response, error) {
+		return nil, errors.New("broken")
+	}
+	fb := func(ctx context.Context, req *Request, err error) *Response {
+		return NewResponse().AddText("sorry")
+	}
+	rec := httptest.NewRecorder()
+	WithFallback(h, fb).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"sessionInfo": {"session": "s"}}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"fulfillmentResponse": {"messages": [{"text": {"text": ["sorry"]}}]}}`, rec.Body.String())
+}
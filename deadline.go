@@ -0,0 +1,163 @@
+package dialogflow
+
+import (
+	"context"
+	"encoding/json"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// WebhookTimeout is the maximum duration dialogflow waits for a webhook to
+// respond before dropping the response
+const WebhookTimeout = 5 * time.Second
+
+// LateResults holds the fulfillments that were computed after the deadline
+// of the request, indexed by session. Results that aren't taken within their
+// ttl are dropped. It is safe for concurrent use.
+type LateResults struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	results map[string]lateResult
+	swept   time.Time
+	now     func() time.Time
+}
+
+type lateResult struct {
+	f       *Fulfillment
+	expires time.Time
+}
+
+// NewLateResults returns a new empty LateResults keeping each result during
+// ttl
+func NewLateResults(ttl time.Duration) *LateResults {
+	return &LateResults{
+		ttl:     ttl,
+		results: make(map[string]lateResult),
+		now:     time.Now,
+	}
+}
+
+// Put stores the fulfillment for the given session, replacing any previous
+// one. Expired results are removed at most once per minute when calling Put.
+func (l *LateResults) Put(session string, f *Fulfillment) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if now.Sub(l.swept) > time.Minute {
+		for k, r := range l.results {
+			if !now.Before(r.expires) {
+				delete(l.results, k)
+			}
+		}
+		l.swept = now
+	}
+	l.results[session] = lateResult{f: f, expires: now.Add(l.ttl)}
+}
+
+// Take returns the fulfillment stored for the given session and removes it.
+// The boolean is false if there was none or if it expired.
+func (l *LateResults) Take(session string) (*Fulfillment, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r, ok := l.results[session]
+	delete(l.results, session)
+	if !ok || !l.now().Before(r.expires) {
+		return nil, false
+	}
+	return r.f, true
+}
+
+// Deadline returns a middleware that answers with the fallback fulfillment if
+// the handler didn't return before the timeout. The fallback can for example
+// contain a FollowupEventInput that triggers a "still working" event.
+// The handler keeps running after the deadline, and its fulfillment is then
+// stored in late (if not nil) using the session of the request, so that a
+// later turn can retrieve it with LateResults.Take.
+// Since the handler may outlive the HTTP request, it is given a context that
+// keeps the values of the original one but is never canceled. A panic in the
+// handler is returned as a *PanicError, which Recover handles like a panic.
+func Deadline(timeout time.Duration, fallback *Fulfillment, late *LateResults) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) (*Fulfillment, error) {
+			type result struct {
+				dff *Fulfillment
+				err error
+			}
+			done := make(chan result, 1)
+			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						done <- result{nil, &PanicError{Value: r, Stack: debug.Stack()}}
+					}
+				}()
+				dff, err := next(detached{ctx}, req)
+				done <- result{dff, err}
+			}()
+
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+			select {
+			case r := <-done:
+				return r.dff, r.err
+			case <-timer.C:
+			case <-ctx.Done():
+			}
+
+			if late != nil {
+				go func() {
+					if r := <-done; r.err == nil && r.dff != nil {
+						late.Put(req.Session, r.dff)
+					}
+				}()
+			}
+			if fallback == nil {
+				return &Fulfillment{}, nil
+			}
+			return copyFulfillment(fallback), nil
+		}
+	}
+}
+
+// copyFulfillment returns a copy of the fulfillment, so that outer
+// middlewares can add messages or alter its contexts without changing the
+// original. The payloads and rich messages are still shared.
+func copyFulfillment(f *Fulfillment) *Fulfillment {
+	if f == nil {
+		return nil
+	}
+	dff := *f
+	dff.FulfillmentMessages = append(Messages(nil), f.FulfillmentMessages...)
+	dff.OutputContexts = nil
+	for _, c := range f.OutputContexts {
+		if c == nil {
+			dff.OutputContexts = append(dff.OutputContexts, nil)
+			continue
+		}
+		cc := *c
+		cc.Parameters = append(json.RawMessage(nil), c.Parameters...)
+		dff.OutputContexts = append(dff.OutputContexts, &cc)
+	}
+	return &dff
+}
+
+// detached is a context that keeps the values of its parent but ignores its
+// deadline and cancellation
+type detached struct {
+	context.Context
+}
+
+// Deadline implements the context.Context interface
+func (detached) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done implements the context.Context interface
+func (detached) Done() <-chan struct{} {
+	return nil
+}
+
+// Err implements the context.Context interface
+func (detached) Err() error {
+	return nil
+}
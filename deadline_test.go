@@ -0,0 +1,147 @@
+package dialogflow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLateResults(t *testing.T) {
+	l := NewLateResults(time.Minute)
+	_, ok := l.Take("session")
+	assert.False(t, ok)
+
+	f := &Fulfillment{FulfillmentText: "done"}
+	l.Put("session", f)
+	got, ok := l.Take("session")
+	assert.True(t, ok)
+	assert.Equal(t, f, got)
+
+	_, ok = l.Take("session")
+	assert.False(t, ok, "should be removed once taken")
+}
+
+func TestLateResults_Expiration(t *testing.T) {
+	now := time.Now()
+	l := NewLateResults(time.Minute)
+	l.now = func() time.Time { return now }
+
+	l.Put("expired", &Fulfillment{})
+	l.Put("taken", &Fulfillment{})
+	now = now.Add(2 * time.Minute)
+	_, ok := l.Take("taken")
+	assert.False(t, ok, "should not return expired results")
+
+	l.Put("fresh", &Fulfillment{})
+	assert.Len(t, l.results, 1, "expired results should be swept")
+	_, ok = l.Take("fresh")
+	assert.True(t, ok)
+}
+
+func TestDeadline(t *testing.T) {
+	fallback := &Fulfillment{FollowupEventInput: FollowupEventInput{Name: "still-working"}}
+	req := &Request{Session: "session"}
+
+	t.Run("should return the handler result in time", func(t *testing.T) {
+		late := NewLateResults(time.Minute)
+		got, err := Deadline(time.Second, fallback, late)(textHandler("hello"))(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", got.FulfillmentText)
+	})
+
+	t.Run("should return the handler error in time", func(t *testing.T) {
+		h := func(ctx context.Context, req *Request) (*Fulfillment, error) {
+			return nil, errors.New("broken")
+		}
+		_, err := Deadline(time.Second, fallback, nil)(h)(context.Background(), req)
+		assert.Error(t, err)
+	})
+
+	t.Run("should use the fallback and stash the late result", func(t *testing.T) {
+		late := NewLateResults(time.Minute)
+		release := make(chan struct{})
+		h := func(ctx context.Context, req *Request) (*Fulfillment, error) {
+			<-release
+			assert.NoError(t, ctx.Err(), "handler context should not be canceled")
+			return &Fulfillment{FulfillmentText: "late"}, nil
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		got, err := Deadline(10*time.Millisecond, fallback, late)(h)(ctx, req)
+		cancel()
+		assert.NoError(t, err)
+		assert.Equal(t, fallback, got)
+		assert.False(t, fallback == got, "fallback should be copied")
+
+		close(release)
+		for start := time.Now(); time.Since(start) < time.Second; time.Sleep(time.Millisecond) {
+			if f, ok := late.Take("session"); ok {
+				assert.Equal(t, "late", f.FulfillmentText)
+				return
+			}
+		}
+		t.Error("late result was never stored")
+	})
+
+	t.Run("should use an empty fulfillment without fallback", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		h := func(ctx context.Context, req *Request) (*Fulfillment, error) {
+			<-release
+			return nil, nil
+		}
+		got, err := Deadline(time.Millisecond, nil, nil)(h)(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, &Fulfillment{}, got)
+	})
+
+	t.Run("should copy the fallback contexts and messages", func(t *testing.T) {
+		fallback := &Fulfillment{
+			FulfillmentMessages: Messages{{RichMessage: Text{Text: []string{"wait"}}}},
+			OutputContexts:      Contexts{{Name: "s/contexts/waiting", LifespanCount: 1}},
+		}
+		release := make(chan struct{})
+		defer close(release)
+		h := func(ctx context.Context, req *Request) (*Fulfillment, error) {
+			<-release
+			return nil, nil
+		}
+		got, err := Deadline(time.Millisecond, fallback, nil)(h)(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, fallback, got)
+		got.OutputContexts[0].LifespanCount = 5
+		got.FulfillmentMessages[0] = Message{}
+		assert.Equal(t, 1, fallback.OutputContexts[0].LifespanCount)
+		assert.Equal(t, Text{Text: []string{"wait"}}, fallback.FulfillmentMessages[0].RichMessage)
+	})
+
+	t.Run("should return panics as errors", func(t *testing.T) {
+		h := func(ctx context.Context, req *Request) (*Fulfillment, error) {
+			var f *Fulfillment
+			return nil, errors.New(f.FulfillmentText)
+		}
+		_, err := Deadline(time.Second, fallback, nil)(h)(context.Background(), req)
+		var perr *PanicError
+		if assert.True(t, errors.As(err, &perr)) {
+			assert.NotEmpty(t, perr.Stack)
+		}
+	})
+
+	t.Run("should be recovered by an outer Recover", func(t *testing.T) {
+		var buf bytes.Buffer
+		sorry := Localized{"": {FulfillmentText: "sorry"}}
+		h := func(ctx context.Context, req *Request) (*Fulfillment, error) {
+			var f *Fulfillment
+			return nil, errors.New(f.FulfillmentText)
+		}
+		mw := Chain(h, Recover(log.New(&buf, "", 0), sorry), Deadline(time.Second, nil, nil))
+		got, err := mw(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, "sorry", got.FulfillmentText)
+		assert.Contains(t, buf.String(), "panic in session session")
+	})
+}
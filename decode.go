@@ -0,0 +1,44 @@
+package dialogflow
+
+import (
+	"net/http"
+
+	"github.com/leboncoin/dialogflow-go-webhook/internal/webhook"
+)
+
+// DefaultMaxBodySize is the maximum body size used by DecodeRequest when
+// none is given
+const DefaultMaxBodySize = webhook.DefaultMaxBodySize
+
+// Errors describing why a request couldn't be decoded. They can be checked
+// against a DecodeError using errors.Is.
+var (
+	ErrEmptyBody     = webhook.ErrEmptyBody
+	ErrBodyTooLarge  = webhook.ErrBodyTooLarge
+	ErrMalformedJSON = webhook.ErrMalformedJSON
+	ErrUnknownField  = webhook.ErrUnknownField
+	ErrMissingField  = webhook.ErrMissingField
+)
+
+// DecodeError is returned by DecodeRequest when the request can't be decoded.
+// It holds the Reason (one of the Err* variables describing the problem), the
+// Field at fault if any, the Offset in the body where the problem occurred if
+// known, and the underlying Err if any.
+type DecodeError = webhook.DecodeError
+
+// DecodeOptions configures the behaviour of DecodeRequest. MaxBodySize is the
+// maximum size of the body in bytes, DefaultMaxBodySize being used if zero.
+// DisallowUnknownFields rejects requests containing fields that aren't part
+// of the Request type.
+type DecodeOptions = webhook.DecodeOptions
+
+// DecodeRequest reads and decodes the body of the HTTP request to a Request.
+// The session and queryResult fields are required. A *DecodeError is
+// returned if anything goes wrong.
+func DecodeRequest(r *http.Request, opts DecodeOptions) (*Request, error) {
+	var dfr Request
+	if err := webhook.DecodeJSON(r, &dfr, opts, "session", "queryResult"); err != nil {
+		return nil, err
+	}
+	return &dfr, nil
+}
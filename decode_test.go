@@ -0,0 +1,90 @@
+package dialogflow
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		opts   DecodeOptions
+		want   *Request
+		reason error
+		field  string
+	}{
+		{
+			"should decode",
+			`{"session": "projects/p/agent/sessions/s", "responseId": "r", "queryResult": {"queryText": "hello"}}`,
+			DecodeOptions{},
+			&Request{Session: "projects/p/agent/sessions/s", ResponseID: "r", QueryResult: QueryResult{QueryText: "hello"}},
+			nil,
+			"",
+		},
+		{
+			"should ignore unknown fields by default",
+			`{"session": "s", "queryResult": {}, "unknown": true}`,
+			DecodeOptions{},
+			&Request{Session: "s"},
+			nil,
+			"",
+		},
+		{"should reject unknown fields", `{"session": "s", "queryResult": {}, "unknown": true}`, DecodeOptions{DisallowUnknownFields: true}, nil, ErrUnknownField, "unknown"},
+		{"should reject empty body", ``, DecodeOptions{}, nil, ErrEmptyBody, ""},
+		{"should reject blank body", "  \n", DecodeOptions{}, nil, ErrEmptyBody, ""},
+		{"should reject large body", `{"session": "s", "queryResult": {}}`, DecodeOptions{MaxBodySize: 10}, nil, ErrBodyTooLarge, ""},
+		{"should reject truncated body", `{"session": "s", "queryResult": {`, DecodeOptions{}, nil, ErrMalformedJSON, ""},
+		{"should reject syntax errors", `{"session": "s",, "queryResult": {}}`, DecodeOptions{}, nil, ErrMalformedJSON, ""},
+		{"should reject wrong types", `{"session": 12, "queryResult": {}}`, DecodeOptions{}, nil, ErrMalformedJSON, "session"},
+		{"should reject trailing data", `{"session": "s", "queryResult": {}} {}`, DecodeOptions{}, nil, ErrMalformedJSON, ""},
+		{"should reject null", `null`, DecodeOptions{}, nil, ErrMissingField, "session"},
+		{"should require session", `{"queryResult": {}}`, DecodeOptions{}, nil, ErrMissingField, "session"},
+		{"should require non empty session", `{"session": "", "queryResult": {}}`, DecodeOptions{}, nil, ErrMissingField, "session"},
+		{"should require query result", `{"session": "s"}`, DecodeOptions{}, nil, ErrMissingField, "queryResult"},
+		{"should require non null query result", `{"session": "s", "queryResult": null}`, DecodeOptions{}, nil, ErrMissingField, "queryResult"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(tt.body))
+			got, err := DecodeRequest(r, tt.opts)
+			if tt.reason == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				return
+			}
+			assert.Nil(t, got)
+			assert.True(t, errors.Is(err, tt.reason), "expected %v, got %v", tt.reason, err)
+			var derr *DecodeError
+			if assert.True(t, errors.As(err, &derr)) {
+				assert.Equal(t, tt.field, derr.Field)
+			}
+		})
+	}
+}
+
+func TestDecodeError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *DecodeError
+		want string
+	}{
+		{"should describe reason", &DecodeError{Reason: ErrEmptyBody}, "dialogflow: invalid request: empty body"},
+		{"should describe field", &DecodeError{Reason: ErrMissingField, Field: "session"}, `dialogflow: invalid request: missing required field "session"`},
+		{
+			"should describe everything",
+			&DecodeError{Reason: ErrMalformedJSON, Field: "session", Offset: 12, Err: errors.New("oops")},
+			`dialogflow: invalid request: malformed json "session" at offset 12: oops`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.err.Error())
+		})
+	}
+}
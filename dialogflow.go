@@ -2,18 +2,17 @@ package dialogflow
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
-	"strings"
 )
 
 // Request is the top-level struct holding all the information
 // Basically links a response ID with a query result.
 type Request struct {
-	Session                     string          `json:"session,omitempty"`
-	ResponseID                  string          `json:"responseId,omitempty"`
-	QueryResult                 QueryResult     `json:"queryResult,omitempty"`
-	OriginalDetectIntentRequest json.RawMessage `json:"originalDetectIntentRequest,omitempty"`
+	Session                     string                      `json:"session,omitempty"`
+	ResponseID                  string                      `json:"responseId,omitempty"`
+	QueryResult                 QueryResult                 `json:"queryResult,omitempty"`
+	OriginalDetectIntentRequest OriginalDetectIntentRequest `json:"originalDetectIntentRequest,omitempty"`
+	AlternativeQueryResults     []QueryResult               `json:"alternativeQueryResults,omitempty"` // v2beta1 only, such as knowledge connector results
 }
 
 // GetParams simply unmarshals the parameters to the given struct and returns
@@ -22,14 +21,29 @@ func (rw *Request) GetParams(i interface{}) error {
 	return json.Unmarshal(rw.QueryResult.Parameters, &i)
 }
 
-// GetContext allows to search in the output contexts of the query
+// GetContext allows to search in the output contexts of the query. The
+// context is matched on its whole short name, ignoring case, and its
+// parameters are unmarshalled to the given struct. ErrContextNotFound is
+// returned if there is no such context.
 func (rw *Request) GetContext(ctx string, i interface{}) error {
+	c, err := rw.QueryResult.OutputContexts.Get(ctx)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(c.Parameters, &i)
+}
+
+// ActiveContexts lists the output contexts of the query along with their
+// remaining lifespan
+func (rw *Request) ActiveContexts() []ActiveContext {
+	var out []ActiveContext
 	for _, c := range rw.QueryResult.OutputContexts {
-		if strings.HasSuffix(c.Name, ctx) {
-			return json.Unmarshal(c.Parameters, &i)
+		if c == nil {
+			continue
 		}
+		out = append(out, ActiveContext{Name: ParseContextName(c.Name), LifespanCount: c.LifespanCount})
 	}
-	return errors.New("context not found")
+	return out
 }
 
 // NewContext is a helper function to create a new named context with params
@@ -51,18 +65,28 @@ func (rw *Request) NewContext(name string, lifespan int, params interface{}) (*C
 
 // QueryResult is the dataset sent back by DialogFlow
 type QueryResult struct {
-	QueryText                 string          `json:"queryText,omitempty"`
-	Action                    string          `json:"action,omitempty"`
-	LanguageCode              string          `json:"languageCode,omitempty"`
-	AllRequiredParamsPresent  bool            `json:"allRequiredParamsPresent,omitempty"`
-	IntentDetectionConfidence float64         `json:"intentDetectionConfidence,omitempty"`
-	Parameters                json.RawMessage `json:"parameters,omitempty"`
-	OutputContexts            []*Context      `json:"outputContexts,omitempty"`
-	Intent                    Intent          `json:"intent,omitempty"`
+	QueryText                   string                   `json:"queryText,omitempty"`
+	Action                      string                   `json:"action,omitempty"`
+	LanguageCode                string                   `json:"languageCode,omitempty"`
+	AllRequiredParamsPresent    bool                     `json:"allRequiredParamsPresent,omitempty"`
+	IntentDetectionConfidence   float64                  `json:"intentDetectionConfidence,omitempty"`
+	SpeechRecognitionConfidence float64                  `json:"speechRecognitionConfidence,omitempty"` // Only set for voice queries, 0 if unavailable
+	Parameters                  json.RawMessage          `json:"parameters,omitempty"`
+	OutputContexts              Contexts                 `json:"outputContexts,omitempty"`
+	Intent                      Intent                   `json:"intent,omitempty"`
+	FulfillmentText             string                   `json:"fulfillmentText,omitempty"`     // The text the agent would have responded with
+	FulfillmentMessages         Messages                 `json:"fulfillmentMessages,omitempty"` // The messages the agent would have responded with
+	WebhookSource               string                   `json:"webhookSource,omitempty"`
+	WebhookPayload              json.RawMessage          `json:"webhookPayload,omitempty"`
+	DiagnosticInfo              json.RawMessage          `json:"diagnosticInfo,omitempty"`
+	SentimentAnalysisResult     *SentimentAnalysisResult `json:"sentimentAnalysisResult,omitempty"` // Only set if sentiment analysis is enabled
+	KnowledgeAnswers            *KnowledgeAnswers        `json:"knowledgeAnswers,omitempty"`        // v2beta1 only, set if knowledge connectors are enabled
 }
 
 // Intent describes the matched intent
 type Intent struct {
-	Name        string `json:"name,omitempty"`
-	DisplayName string `json:"displayName,omitempty"`
+	Name           string `json:"name,omitempty"`
+	DisplayName    string `json:"displayName,omitempty"`
+	IsFallback     bool   `json:"isFallback,omitempty"`
+	EndInteraction bool   `json:"endInteraction,omitempty"`
 }
@@ -73,15 +73,43 @@ func TestRequest_GetContext(t *testing.T) {
 			out{"in", "out"},
 			false,
 		},
+		{
+			"should match full context names",
+			Contexts{{"projects/p/agent/sessions/s/contexts/hello-ctx", 1, []byte(`{"in": "in", "out": "out"}`)}},
+			"hello-ctx",
+			out{"in", "out"},
+			false,
+		},
+		{
+			"should match exactly",
+			Contexts{
+				{"projects/p/agent/sessions/s/contexts/pre-order", 1, []byte(`{"in": "pre", "out": "pre"}`)},
+				{"projects/p/agent/sessions/s/contexts/order", 1, []byte(`{"in": "in", "out": "out"}`)},
+			},
+			"order",
+			out{"in", "out"},
+			false,
+		},
+		{
+			"should not match suffix",
+			Contexts{{"projects/p/agent/sessions/s/contexts/pre-order", 1, []byte(`{"in": "pre", "out": "pre"}`)}},
+			"order",
+			out{},
+			true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			rw := &Request{QueryResult: QueryResult{OutputContexts: tt.fields}}
 
 			var output out
-			if err := rw.GetContext(tt.ctx, &output); (err != nil) != tt.wantErr {
+			err := rw.GetContext(tt.ctx, &output)
+			if (err != nil) != tt.wantErr {
 				t.Errorf("Request.GetContext() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if tt.wantErr {
+				assert.Equal(t, ErrContextNotFound, err)
+			}
 			assert.Equal(t, output, tt.expected, "should match")
 		})
 	}
@@ -96,7 +124,7 @@ func TestRequest_NewContext(t *testing.T) {
 		Session                     string
 		ResponseID                  string
 		QueryResult                 QueryResult
-		OriginalDetectIntentRequest json.RawMessage
+		OriginalDetectIntentRequest OriginalDetectIntentRequest
 	}
 	std := fields{Session: "session"}
 	type args struct {
@@ -147,3 +175,68 @@ func TestRequest_NewContext(t *testing.T) {
 		})
 	}
 }
+
+func TestRequest_RoundTrip(t *testing.T) {
+	in := []byte(`{
+		"session": "projects/p/agent/sessions/1234",
+		"responseId": "5678",
+		"queryResult": {
+			"queryText": "I want a pizza",
+			"action": "order.pizza",
+			"languageCode": "en",
+			"allRequiredParamsPresent": true,
+			"intentDetectionConfidence": 0.92,
+			"speechRecognitionConfidence": 0.87,
+			"parameters": {"size": "large"},
+			"outputContexts": [{"name": "projects/p/agent/sessions/1234/contexts/order", "lifespanCount": 2, "parameters": {"size": "large"}}],
+			"intent": {"name": "projects/p/agent/intents/abcd", "displayName": "order.pizza", "isFallback": true, "endInteraction": true},
+			"fulfillmentText": "One large pizza coming up",
+			"fulfillmentMessages": [
+				{"text": {"text": ["One large pizza coming up"]}},
+				{"platform": "ACTIONS_ON_GOOGLE", "simpleResponses": {"simpleResponses": [{"textToSpeech": "One large pizza"}]}},
+				{"platform": "ACTIONS_ON_GOOGLE", "basicCard": {"title": "Pizza", "image": {"imageUri": "https://example.com/pizza.png"}}},
+				{"platform": "ACTIONS_ON_GOOGLE", "suggestions": {"suggestions": [{"title": "Yes"}, {"title": "No"}]}},
+				{"payload": {"custom": {"key": "value"}}}
+			],
+			"webhookSource": "webhook",
+			"webhookPayload": {"google": {"expectUserResponse": true}},
+			"diagnosticInfo": {"webhook_latency_ms": 42}
+		},
+		"originalDetectIntentRequest": {"source": "google", "version": "2", "payload": {"isInSandbox": true}}
+	}`)
+
+	var rw Request
+	if err := json.Unmarshal(in, &rw); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	qr := rw.QueryResult
+	assert.Equal(t, "One large pizza coming up", qr.FulfillmentText)
+	assert.Equal(t, 0.87, qr.SpeechRecognitionConfidence)
+	assert.True(t, qr.Intent.IsFallback)
+	assert.True(t, qr.Intent.EndInteraction)
+	assert.Equal(t, "webhook", qr.WebhookSource)
+	if assert.Len(t, qr.FulfillmentMessages, 5) {
+		assert.Equal(t, Text{Text: []string{"One large pizza coming up"}}, qr.FulfillmentMessages[0].RichMessage)
+		assert.Equal(t, ActionsOnGoogle, qr.FulfillmentMessages[2].Platform)
+		assert.Equal(t, "Pizza", qr.FulfillmentMessages[2].RichMessage.(BasicCard).Title)
+	}
+
+	assert.Equal(t, ActionsOnGoogle, rw.OriginalDetectIntentRequest.Platform())
+
+	if err := PayloadTester(rw, in); err != nil {
+		t.Errorf("round trip error = %v", err)
+	}
+}
+
+func TestRequest_ActiveContexts(t *testing.T) {
+	rw := &Request{QueryResult: QueryResult{OutputContexts: Contexts{
+		{"projects/p/agent/sessions/s/contexts/order", 2, nil},
+		{"projects/p/agent/sessions/s/contexts/actions_capability_screen_output", 0, nil},
+	}}}
+	want := []ActiveContext{
+		{ContextName{"projects/p/agent/sessions/s", "order"}, 2},
+		{ContextName{"projects/p/agent/sessions/s", "actions_capability_screen_output"}, 0},
+	}
+	assert.Equal(t, want, rw.ActiveContexts())
+	assert.Nil(t, (&Request{}).ActiveContexts())
+}
@@ -0,0 +1,355 @@
+package dialogflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayouts are the layouts dialogflow uses for dates and times
+var timeLayouts = []string{time.RFC3339, "2006-01-02", "15:04:05"}
+
+// parseTime parses a date, a time, or a date and time sent by dialogflow.
+// An empty string gives a zero time.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	for _, l := range timeLayouts {
+		if t, err := time.Parse(l, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("dialogflow: unable to parse time %q", s)
+}
+
+// parsePeriod parses two times separated by a slash, such as
+// 2018-01-01/2018-01-31
+func parsePeriod(s string) (start, end time.Time, err error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 {
+		return start, end, fmt.Errorf("dialogflow: unable to parse period %q", s)
+	}
+	if start, err = parseTime(parts[0]); err != nil {
+		return start, end, err
+	}
+	end, err = parseTime(parts[1])
+	return start, end, err
+}
+
+// unmarshalString returns the string or number contained in the raw JSON.
+// The boolean is false if the data is neither.
+func unmarshalString(b []byte) (string, bool) {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		return s, true
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err == nil {
+		return n.String(), true
+	}
+	return "", false
+}
+
+// DateTime is a @sys.date-time entity. Dialogflow either sends a single date
+// and time, or a period.
+type DateTime struct {
+	Time  time.Time // Set when a single date and time was given
+	Start time.Time // Set when a period was given
+	End   time.Time // Set when a period was given
+}
+
+// IsPeriod returns true if the entity is a period rather than a single date
+// and time
+func (d DateTime) IsPeriod() bool {
+	return !d.Start.IsZero() || !d.End.IsZero()
+}
+
+// UnmarshalJSON implements the Unmarshaler interface for JSON parsing.
+// Accepts a string, or an object with a date_time, startDateTime/endDateTime,
+// startDate/endDate or startTime/endTime pair.
+func (d *DateTime) UnmarshalJSON(b []byte) error {
+	var err error
+	*d = DateTime{}
+	if s, ok := unmarshalString(b); ok {
+		if strings.Contains(s, "/") {
+			d.Start, d.End, err = parsePeriod(s)
+			return err
+		}
+		d.Time, err = parseTime(s)
+		return err
+	}
+
+	var o struct {
+		DateTime      string `json:"date_time"`
+		StartDateTime string `json:"startDateTime"`
+		EndDateTime   string `json:"endDateTime"`
+		StartDate     string `json:"startDate"`
+		EndDate       string `json:"endDate"`
+		StartTime     string `json:"startTime"`
+		EndTime       string `json:"endTime"`
+	}
+	if err = json.Unmarshal(b, &o); err != nil {
+		return err
+	}
+	switch {
+	case o.DateTime != "":
+		d.Time, err = parseTime(o.DateTime)
+		return err
+	case o.StartDateTime != "" || o.EndDateTime != "":
+		return parseBounds(o.StartDateTime, o.EndDateTime, &d.Start, &d.End)
+	case o.StartDate != "" || o.EndDate != "":
+		return parseBounds(o.StartDate, o.EndDate, &d.Start, &d.End)
+	default:
+		return parseBounds(o.StartTime, o.EndTime, &d.Start, &d.End)
+	}
+}
+
+// parseBounds parses the start and end of a period
+func parseBounds(s, e string, start, end *time.Time) error {
+	var err error
+	if *start, err = parseTime(s); err != nil {
+		return err
+	}
+	*end, err = parseTime(e)
+	return err
+}
+
+// DatePeriod is a @sys.date-period entity
+type DatePeriod struct {
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+// UnmarshalJSON implements the Unmarshaler interface for JSON parsing.
+// Accepts a start/end string, or an object with startDate and endDate.
+func (d *DatePeriod) UnmarshalJSON(b []byte) error {
+	var err error
+	*d = DatePeriod{}
+	if s, ok := unmarshalString(b); ok {
+		if s == "" {
+			return nil
+		}
+		d.StartDate, d.EndDate, err = parsePeriod(s)
+		return err
+	}
+	var o struct {
+		StartDate string `json:"startDate"`
+		EndDate   string `json:"endDate"`
+	}
+	if err = json.Unmarshal(b, &o); err != nil {
+		return err
+	}
+	return parseBounds(o.StartDate, o.EndDate, &d.StartDate, &d.EndDate)
+}
+
+// TimePeriod is a @sys.time-period entity
+type TimePeriod struct {
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// UnmarshalJSON implements the Unmarshaler interface for JSON parsing.
+// Accepts a start/end string, or an object with startTime and endTime.
+func (t *TimePeriod) UnmarshalJSON(b []byte) error {
+	var err error
+	*t = TimePeriod{}
+	if s, ok := unmarshalString(b); ok {
+		if s == "" {
+			return nil
+		}
+		t.StartTime, t.EndTime, err = parsePeriod(s)
+		return err
+	}
+	var o struct {
+		StartTime string `json:"startTime"`
+		EndTime   string `json:"endTime"`
+	}
+	if err = json.Unmarshal(b, &o); err != nil {
+		return err
+	}
+	return parseBounds(o.StartTime, o.EndTime, &t.StartTime, &t.EndTime)
+}
+
+// Amount is a number associated to a unit. It is the base of the
+// @sys.duration, @sys.age and @sys.temperature entities.
+type Amount struct {
+	Amount float64 `json:"amount"`
+	Unit   string  `json:"unit"`
+}
+
+// UnmarshalJSON implements the Unmarshaler interface for JSON parsing.
+// Accepts an object with an amount and a unit, the amount being either a
+// number or a string, or a string such as "10 min".
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	var err error
+	*a = Amount{}
+	if s, ok := unmarshalString(b); ok {
+		a.Amount, a.Unit, err = parseAmount(s)
+		return err
+	}
+	var o struct {
+		Amount json.RawMessage `json:"amount"`
+		Unit   string          `json:"unit"`
+	}
+	if err = json.Unmarshal(b, &o); err != nil {
+		return err
+	}
+	a.Unit = o.Unit
+	if len(o.Amount) > 0 {
+		s, ok := unmarshalString(o.Amount)
+		if !ok {
+			return fmt.Errorf("dialogflow: unable to parse amount %s", o.Amount)
+		}
+		a.Amount, _, err = parseAmount(s)
+	}
+	return err
+}
+
+// parseAmount parses an amount optionally followed by a unit
+func parseAmount(s string) (float64, string, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return 0, "", nil
+	}
+	n, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("dialogflow: unable to parse amount %q", s)
+	}
+	return n, strings.Join(fields[1:], " "), nil
+}
+
+// durationUnits maps the duration units sent by dialogflow to their value
+var durationUnits = map[string]time.Duration{
+	"s":   time.Second,
+	"min": time.Minute,
+	"h":   time.Hour,
+	"day": 24 * time.Hour,
+	"wk":  7 * 24 * time.Hour,
+}
+
+// Duration is a @sys.duration entity
+type Duration struct {
+	Amount
+}
+
+// Duration converts the entity to a time.Duration. The boolean is false if
+// the unit can't be converted, such as months or years.
+func (d Duration) Duration() (time.Duration, bool) {
+	u, ok := durationUnits[d.Unit]
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(d.Amount.Amount * float64(u)), true
+}
+
+// Age is a @sys.age entity
+type Age struct {
+	Amount
+}
+
+// Temperature is a @sys.temperature entity
+type Temperature struct {
+	Amount
+}
+
+// UnitCurrency is a @sys.unit-currency entity
+type UnitCurrency struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// UnmarshalJSON implements the Unmarshaler interface for JSON parsing.
+// Accepts an object with an amount and a currency, or a string such as
+// "10 USD".
+func (u *UnitCurrency) UnmarshalJSON(b []byte) error {
+	var a Amount
+	var o struct {
+		Currency string `json:"currency"`
+	}
+	if err := a.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	if _, ok := unmarshalString(b); !ok {
+		if err := json.Unmarshal(b, &o); err != nil {
+			return err
+		}
+		a.Unit = o.Currency
+	}
+	*u = UnitCurrency{Amount: a.Amount, Currency: a.Unit}
+	return nil
+}
+
+// NumberSequence is a @sys.number-sequence entity. Leading zeros are kept.
+type NumberSequence string
+
+// UnmarshalJSON implements the Unmarshaler interface for JSON parsing.
+// Accepts a string or a number.
+func (n *NumberSequence) UnmarshalJSON(b []byte) error {
+	s, ok := unmarshalString(b)
+	if !ok {
+		return fmt.Errorf("dialogflow: unable to parse number sequence %s", b)
+	}
+	*n = NumberSequence(s)
+	return nil
+}
+
+// PhoneNumber is a @sys.phone-number entity
+type PhoneNumber string
+
+// UnmarshalJSON implements the Unmarshaler interface for JSON parsing.
+// Accepts a string, a number, or an object with a phone-number key.
+func (p *PhoneNumber) UnmarshalJSON(b []byte) error {
+	if s, ok := unmarshalString(b); ok {
+		*p = PhoneNumber(s)
+		return nil
+	}
+	var o struct {
+		PhoneNumber string `json:"phone-number"`
+	}
+	if err := json.Unmarshal(b, &o); err != nil {
+		return err
+	}
+	*p = PhoneNumber(o.PhoneNumber)
+	return nil
+}
+
+// URL is a @sys.url entity
+type URL struct {
+	Raw string   // The URL as sent by dialogflow
+	URL *url.URL // The parsed URL, nil if it couldn't be parsed
+}
+
+// UnmarshalJSON implements the Unmarshaler interface for JSON parsing.
+// Accepts a string, or an object with a url key. URLs without a scheme are
+// parsed as http URLs.
+func (u *URL) UnmarshalJSON(b []byte) error {
+	*u = URL{}
+	s, ok := unmarshalString(b)
+	if !ok {
+		var o struct {
+			URL string `json:"url"`
+		}
+		if err := json.Unmarshal(b, &o); err != nil {
+			return err
+		}
+		s = o.URL
+	}
+	u.Raw = s
+	if s == "" {
+		return nil
+	}
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+	u.URL, _ = url.Parse(s)
+	return nil
+}
+
+// String returns the URL as sent by dialogflow
+func (u URL) String() string {
+	return u.Raw
+}
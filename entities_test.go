@@ -0,0 +1,293 @@
+package dialogflow
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustTime(s string) time.Time {
+	t, err := parseTime(s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestDateTime_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []byte
+		want    DateTime
+		wantErr bool
+	}{
+		{"should unmarshal string", []byte(`"2018-10-01T12:00:00+02:00"`), DateTime{Time: mustTime("2018-10-01T12:00:00+02:00")}, false},
+		{"should unmarshal date", []byte(`"2018-10-01"`), DateTime{Time: mustTime("2018-10-01")}, false},
+		{"should unmarshal empty string", []byte(`""`), DateTime{}, false},
+		{"should unmarshal date_time", []byte(`{"date_time": "2018-10-01T12:00:00+02:00"}`), DateTime{Time: mustTime("2018-10-01T12:00:00+02:00")}, false},
+		{
+			"should unmarshal date time period",
+			[]byte(`{"startDateTime": "2018-10-01T12:00:00+02:00", "endDateTime": "2018-10-01T14:00:00+02:00"}`),
+			DateTime{Start: mustTime("2018-10-01T12:00:00+02:00"), End: mustTime("2018-10-01T14:00:00+02:00")},
+			false,
+		},
+		{
+			"should unmarshal date period",
+			[]byte(`{"startDate": "2018-10-01T00:00:00+02:00", "endDate": "2018-10-07T23:59:59+02:00"}`),
+			DateTime{Start: mustTime("2018-10-01T00:00:00+02:00"), End: mustTime("2018-10-07T23:59:59+02:00")},
+			false,
+		},
+		{
+			"should unmarshal time period",
+			[]byte(`{"startTime": "2018-10-01T12:00:00+02:00", "endTime": "2018-10-01T16:00:00+02:00"}`),
+			DateTime{Start: mustTime("2018-10-01T12:00:00+02:00"), End: mustTime("2018-10-01T16:00:00+02:00")},
+			false,
+		},
+		{"should unmarshal period string", []byte(`"2018-10-01/2018-10-07"`), DateTime{Start: mustTime("2018-10-01"), End: mustTime("2018-10-07")}, false},
+		{"should fail on invalid time", []byte(`"tomorrow"`), DateTime{}, true},
+		{"should fail on invalid json", []byte(`[]`), DateTime{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got DateTime
+			if err := json.Unmarshal(tt.in, &got); (err != nil) != tt.wantErr {
+				t.Errorf("DateTime.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, !tt.want.Start.IsZero(), got.IsPeriod())
+		})
+	}
+}
+
+func TestDatePeriod_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []byte
+		want    DatePeriod
+		wantErr bool
+	}{
+		{
+			"should unmarshal object",
+			[]byte(`{"startDate": "2018-10-01T00:00:00+02:00", "endDate": "2018-10-31T23:59:59+02:00"}`),
+			DatePeriod{mustTime("2018-10-01T00:00:00+02:00"), mustTime("2018-10-31T23:59:59+02:00")},
+			false,
+		},
+		{"should unmarshal string", []byte(`"2018-10-01/2018-10-31"`), DatePeriod{mustTime("2018-10-01"), mustTime("2018-10-31")}, false},
+		{"should unmarshal empty string", []byte(`""`), DatePeriod{}, false},
+		{"should fail on invalid string", []byte(`"2018-10-01"`), DatePeriod{}, true},
+		{"should fail on invalid date", []byte(`{"startDate": "october"}`), DatePeriod{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got DatePeriod
+			if err := json.Unmarshal(tt.in, &got); (err != nil) != tt.wantErr {
+				t.Errorf("DatePeriod.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTimePeriod_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []byte
+		want    TimePeriod
+		wantErr bool
+	}{
+		{
+			"should unmarshal object",
+			[]byte(`{"startTime": "2018-10-01T13:00:00+02:00", "endTime": "2018-10-01T14:00:00+02:00"}`),
+			TimePeriod{mustTime("2018-10-01T13:00:00+02:00"), mustTime("2018-10-01T14:00:00+02:00")},
+			false,
+		},
+		{"should unmarshal string", []byte(`"13:00:00/14:00:00"`), TimePeriod{mustTime("13:00:00"), mustTime("14:00:00")}, false},
+		{"should unmarshal empty string", []byte(`""`), TimePeriod{}, false},
+		{"should fail on invalid json", []byte(`true`), TimePeriod{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got TimePeriod
+			if err := json.Unmarshal(tt.in, &got); (err != nil) != tt.wantErr {
+				t.Errorf("TimePeriod.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAmount_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []byte
+		want    Amount
+		wantErr bool
+	}{
+		{"should unmarshal object", []byte(`{"amount": 10, "unit": "min"}`), Amount{10, "min"}, false},
+		{"should unmarshal string amount", []byte(`{"amount": "2.5", "unit": "h"}`), Amount{2.5, "h"}, false},
+		{"should unmarshal string", []byte(`"30 year"`), Amount{30, "year"}, false},
+		{"should unmarshal number", []byte(`12`), Amount{12, ""}, false},
+		{"should unmarshal empty string", []byte(`""`), Amount{}, false},
+		{"should fail on invalid amount", []byte(`{"amount": "ten", "unit": "min"}`), Amount{}, true},
+		{"should fail on invalid amount type", []byte(`{"amount": [], "unit": "min"}`), Amount{}, true},
+		{"should fail on invalid string", []byte(`"ten min"`), Amount{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Amount
+			if err := json.Unmarshal(tt.in, &got); (err != nil) != tt.wantErr {
+				t.Errorf("Amount.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDuration_Duration(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   time.Duration
+		wantOk bool
+	}{
+		{`{"amount": 10, "unit": "min"}`, 10 * time.Minute, true},
+		{`{"amount": 1.5, "unit": "h"}`, 90 * time.Minute, true},
+		{`{"amount": 2, "unit": "day"}`, 48 * time.Hour, true},
+		{`{"amount": 3, "unit": "mo"}`, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var d Duration
+			assert.NoError(t, json.Unmarshal([]byte(tt.in), &d))
+			got, ok := d.Duration()
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAgeTemperature_UnmarshalJSON(t *testing.T) {
+	var p struct {
+		Age         Age         `json:"age"`
+		Temperature Temperature `json:"temperature"`
+	}
+	in := `{"age": {"amount": 30, "unit": "year"}, "temperature": {"amount": -5, "unit": "C"}}`
+	assert.NoError(t, json.Unmarshal([]byte(in), &p))
+	assert.Equal(t, Age{Amount{30, "year"}}, p.Age)
+	assert.Equal(t, Temperature{Amount{-5, "C"}}, p.Temperature)
+}
+
+func TestUnitCurrency_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []byte
+		want    UnitCurrency
+		wantErr bool
+	}{
+		{"should unmarshal object", []byte(`{"amount": 10, "currency": "USD"}`), UnitCurrency{10, "USD"}, false},
+		{"should unmarshal string", []byte(`"9.99 EUR"`), UnitCurrency{9.99, "EUR"}, false},
+		{"should fail on invalid amount", []byte(`{"amount": "a lot", "currency": "USD"}`), UnitCurrency{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got UnitCurrency
+			if err := json.Unmarshal(tt.in, &got); (err != nil) != tt.wantErr {
+				t.Errorf("UnitCurrency.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNumberSequence_UnmarshalJSON(t *testing.T) {
+	var n NumberSequence
+	assert.NoError(t, json.Unmarshal([]byte(`"0042"`), &n))
+	assert.Equal(t, NumberSequence("0042"), n)
+	assert.NoError(t, json.Unmarshal([]byte(`1234`), &n))
+	assert.Equal(t, NumberSequence("1234"), n)
+	assert.Error(t, json.Unmarshal([]byte(`{}`), &n))
+}
+
+func TestPhoneNumber_UnmarshalJSON(t *testing.T) {
+	var p PhoneNumber
+	assert.NoError(t, json.Unmarshal([]byte(`"+33 6 12 34 56 78"`), &p))
+	assert.Equal(t, PhoneNumber("+33 6 12 34 56 78"), p)
+	assert.NoError(t, json.Unmarshal([]byte(`612345678`), &p))
+	assert.Equal(t, PhoneNumber("612345678"), p)
+	assert.NoError(t, json.Unmarshal([]byte(`{"phone-number": "0612345678"}`), &p))
+	assert.Equal(t, PhoneNumber("0612345678"), p)
+	assert.Error(t, json.Unmarshal([]byte(`[]`), &p))
+}
+
+func TestURL_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []byte
+		raw     string
+		host    string
+		wantErr bool
+	}{
+		{"should unmarshal full url", []byte(`"https://www.leboncoin.fr/annonces"`), "https://www.leboncoin.fr/annonces", "www.leboncoin.fr", false},
+		{"should unmarshal url without scheme", []byte(`"leboncoin.fr"`), "leboncoin.fr", "leboncoin.fr", false},
+		{"should unmarshal object", []byte(`{"url": "https://example.com"}`), "https://example.com", "example.com", false},
+		{"should unmarshal empty string", []byte(`""`), "", "", false},
+		{"should fail on invalid json", []byte(`[]`), "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got URL
+			if err := json.Unmarshal(tt.in, &got); (err != nil) != tt.wantErr {
+				t.Errorf("URL.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, tt.raw, got.String())
+			if tt.host == "" {
+				assert.Nil(t, got.URL)
+				return
+			}
+			assert.Equal(t, tt.host, got.URL.Host)
+		})
+	}
+}
+
+func TestEntities_GetParams(t *testing.T) {
+	type params struct {
+		When     DateTime       `json:"date-time"`
+		Duration Duration       `json:"duration"`
+		Price    UnitCurrency   `json:"unit-currency"`
+		Code     NumberSequence `json:"number-sequence"`
+		Phone    PhoneNumber    `json:"phone-number"`
+		Site     URL            `json:"url"`
+	}
+	rw := &Request{QueryResult: QueryResult{Parameters: []byte(`{
+		"date-time": "2018-10-01T12:00:00+02:00",
+		"duration": {"amount": 20, "unit": "min"},
+		"unit-currency": {"amount": 15, "currency": "EUR"},
+		"number-sequence": "007",
+		"phone-number": "0612345678",
+		"url": "leboncoin.fr"
+	}`)}}
+	var p params
+	assert.NoError(t, rw.GetParams(&p))
+	assert.Equal(t, mustTime("2018-10-01T12:00:00+02:00"), p.When.Time)
+	d, _ := p.Duration.Duration()
+	assert.Equal(t, 20*time.Minute, d)
+	assert.Equal(t, UnitCurrency{15, "EUR"}, p.Price)
+	assert.Equal(t, NumberSequence("007"), p.Code)
+	assert.Equal(t, PhoneNumber("0612345678"), p.Phone)
+	assert.Equal(t, "leboncoin.fr", p.Site.URL.Host)
+}
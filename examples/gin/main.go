@@ -1,7 +1,7 @@
 package main
 
 import (
-	"net/http"
+	"context"
 
 	"github.com/gin-gonic/gin"
 	df "github.com/leboncoin/dialogflow-go-webhook"
@@ -13,26 +13,18 @@ type params struct {
 	Age    int    `json:"age"`
 }
 
-func webhook(c *gin.Context) {
+func webhook(ctx context.Context, dfr *df.Request) (*df.Fulfillment, error) {
 	var err error
-	var dfr *df.Request
 	var p params
 
-	if err = c.BindJSON(&dfr); err != nil {
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
 	// Retrieve the params of the request
 	if err = dfr.GetParams(&p); err != nil {
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
+		return nil, err
 	}
 
 	// Retrieve a specific context
 	if err = dfr.GetContext("my-awesome-context", &p); err != nil {
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
+		return nil, err
 	}
 
 	// Do things with the context you just retrieved
@@ -44,12 +36,20 @@ func webhook(c *gin.Context) {
 			{RichMessage: df.Text{Text: []string{"hello"}}},
 		},
 	}
-	c.JSON(http.StatusOK, dff)
+	return dff, nil
 }
 
 func main() {
+	dr := df.NewRouter()
+	dr.Use(
+		df.Logging(nil),
+		df.DefaultContext("my-awesome-context", 5, params{}),
+		df.AppendSuggestions("Yes", "No"),
+	)
+	dr.HandleIntent("my-awesome-intent", webhook)
+
 	r := gin.Default()
-	r.POST("/webhook", webhook)
+	r.POST("/webhook", gin.WrapH(dr))
 	if err := r.Run("127.0.0.1:8001"); err != nil {
 		panic(err)
 	}
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"log"
 	"net/http"
 
@@ -14,30 +14,18 @@ type params struct {
 	Age    int    `json:"age"`
 }
 
-func webhook(rw http.ResponseWriter, req *http.Request) {
+func webhook(ctx context.Context, dfr *df.Request) (*df.Fulfillment, error) {
 	var err error
-	var dfr *df.Request
 	var p params
 
-	decoder := json.NewDecoder(req.Body)
-	if err = decoder.Decode(&dfr); err != nil {
-		rw.WriteHeader(http.StatusBadRequest)
-		return
-	}
-	defer req.Body.Close()
-
-	// Filter on action, using a switch for example
-
 	// Retrieve the params of the request
 	if err = dfr.GetParams(&p); err != nil {
-		rw.WriteHeader(http.StatusBadRequest)
-		return
+		return nil, err
 	}
 
 	// Retrieve a specific context
 	if err = dfr.GetContext("my-awesome-context", &p); err != nil {
-		rw.WriteHeader(http.StatusBadRequest)
-		return
+		return nil, err
 	}
 
 	// Do things with the context you just retrieved
@@ -47,12 +35,19 @@ func webhook(rw http.ResponseWriter, req *http.Request) {
 			{RichMessage: df.Text{Text: []string{"hello"}}},
 		},
 	}
-	rw.Header().Set("Content-Type", "application/json")
-	rw.WriteHeader(http.StatusOK)
-	json.NewEncoder(rw).Encode(dff)
+	return dff, nil
 }
 
 func main() {
-	http.HandleFunc("/webhook", webhook)
+	sorry := df.Localized{
+		"":   {FulfillmentText: "Sorry, something went wrong."},
+		"fr": {FulfillmentText: "Désolé, une erreur est survenue."},
+	}
+
+	r := df.NewRouter()
+	r.Fallback = sorry.Fallback()
+	r.Use(df.Logging(nil), df.Recover(nil, sorry))
+	r.HandleIntent("my-awesome-intent", webhook)
+	http.Handle("/webhook", r)
 	log.Fatal(http.ListenAndServe(":8082", nil))
 }
@@ -64,6 +64,34 @@ func (m *Message) MarshalJSON() ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
+// UnmarshalJSON implements the Unmarshaler interface for the JSON type.
+// The rich message is decoded to the type registered for its key, see
+// RegisterRichMessage.
+func (m *Message) UnmarshalJSON(b []byte) error {
+	var err error
+	var fields map[string]json.RawMessage
+
+	if err = json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	*m = Message{}
+	for k, v := range fields {
+		if k == "platform" {
+			if err = json.Unmarshal(v, &m.Platform); err != nil {
+				return err
+			}
+			continue
+		}
+		if m.RichMessage != nil {
+			return fmt.Errorf("dialogflow: message has more than one rich message (%q and %q)", m.RichMessage.GetKey(), k)
+		}
+		if m.RichMessage, err = newRichMessage(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ForGoogle takes a rich message wraps it in a message with the appropriate
 // platform set
 func ForGoogle(r RichMessage) Message {
@@ -1,6 +1,7 @@
 package dialogflow
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
@@ -93,3 +94,83 @@ func TestMessage_MarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestMessage_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []byte
+		want    Message
+		wantErr bool
+	}{
+		{"should unmarshal empty message", []byte(`{}`), Message{}, false},
+		{"should unmarshal platform", []byte(`{"platform": "SLACK"}`), Message{Platform: Slack}, false},
+		{
+			"should unmarshal text",
+			[]byte(`{"text": {"text": ["hello"]}}`),
+			Message{RichMessage: Text{Text: []string{"hello"}}},
+			false,
+		},
+		{
+			"should unmarshal platform and message",
+			[]byte(`{"platform": "ACTIONS_ON_GOOGLE", "simpleResponses": {"simpleResponses": [{"textToSpeech": "hi", "displayText": "hi"}]}}`),
+			ForGoogle(SingleSimpleResponse("hi", "hi")),
+			false,
+		},
+		{
+			"should unmarshal list select",
+			[]byte(`{"listSelect": {"title": "list", "items": [{"info": {"key": "one"}, "title": "One"}]}}`),
+			Message{RichMessage: ListSelect{Title: "list", Items: []Item{{Info: SelectItemInfo{Key: "one"}, Title: "One"}}}},
+			false,
+		},
+		{
+			"should unmarshal payload",
+			[]byte(`{"payload": {"hello": "world"}}`),
+			Message{RichMessage: PayloadWrapper{Payload: map[string]interface{}{"hello": "world"}}},
+			false,
+		},
+		{
+			"should keep unknown messages",
+			[]byte(`{"tableCard": {"title": "table"}}`),
+			Message{RichMessage: UnknownMessage{Key: "tableCard", Raw: []byte(`{"title": "table"}`)}},
+			false,
+		},
+		{"should fail on multiple messages", []byte(`{"text": {"text": ["hello"]}, "image": {"imageUri": "uri"}}`), Message{}, true},
+		{"should fail on invalid message", []byte(`{"text": {"text": "hello"}}`), Message{}, true},
+		{"should fail on invalid platform", []byte(`{"platform": 12}`), Message{}, true},
+		{"should fail on invalid json", []byte(`[]`), Message{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Message
+			err := m.UnmarshalJSON(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Message.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(m, tt.want) {
+				t.Errorf("Message.UnmarshalJSON() = %#v, want %#v", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessages_RoundTrip(t *testing.T) {
+	in := []byte(`[
+		{"text": {"text": ["hello"]}},
+		{"platform": "FACEBOOK", "quickReplies": {"title": "Pick one", "quickReplies": ["a", "b"]}},
+		{"card": {"title": "card", "imageUri": "https://example.com/image.png", "buttons": [{"text": "go", "postback": "go"}]}},
+		{"platform": "ACTIONS_ON_GOOGLE", "carouselSelect": {"items": [{"info": {"key": "one", "synonyms": ["first"]}, "title": "One"}]}},
+		{"platform": "ACTIONS_ON_GOOGLE", "linkOutSuggestion": {"suggestionName": "site", "uri": "https://example.com"}},
+		{"platform": "ACTIONS_ON_GOOGLE", "tableCard": {"title": "unknown"}}
+	]`)
+	var m Messages
+	if err := json.Unmarshal(in, &m); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if err := PayloadTester(m, in); err != nil {
+		t.Errorf("round trip error = %v", err)
+	}
+}
@@ -1,5 +1,7 @@
 module github.com/leboncoin/dialogflow-go-webhook
 
+go 1.13
+
 require (
 	github.com/davecgh/go-spew v1.1.1
 	github.com/gin-contrib/sse v0.0.0-20170109093832-22d885f9ecc7
@@ -0,0 +1,151 @@
+package dialogflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotGoogle is returned when trying to access the Actions on Google
+// payload of a request coming from another source
+var ErrNotGoogle = errors.New("dialogflow: request doesn't come from Actions on Google")
+
+// Conversation types
+const (
+	ConversationNew    = "NEW"
+	ConversationActive = "ACTIVE"
+)
+
+// User verification statuses
+const (
+	UserVerified = "VERIFIED"
+	UserGuest    = "GUEST"
+)
+
+func init() {
+	RegisterPayloadDecoder("google", func(raw json.RawMessage) (interface{}, error) {
+		var p GooglePayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, err
+		}
+		return &p, nil
+	})
+}
+
+// GooglePayload is the payload of the original request when it comes from
+// Actions on Google
+type GooglePayload struct {
+	User              GoogleUser         `json:"user,omitempty"`              // The user that initiated the conversation
+	Conversation      GoogleConversation `json:"conversation,omitempty"`      // The conversation itself
+	Inputs            []GoogleInput      `json:"inputs,omitempty"`            // The inputs of the user
+	Surface           Surface            `json:"surface,omitempty"`           // The surface the user is interacting with
+	AvailableSurfaces []Surface          `json:"availableSurfaces,omitempty"` // The other surfaces the user can switch to
+	IsInSandbox       bool               `json:"isInSandbox,omitempty"`       // Whether the request is done in sandbox mode
+	RequestType       string             `json:"requestType,omitempty"`       // The type of request (SIMULATOR, …)
+}
+
+// GoogleUser describes the user that initiated the conversation
+type GoogleUser struct {
+	UserID                 string     `json:"userId,omitempty"`                 // Deprecated by Google, use UserStorage instead
+	IDToken                string     `json:"idToken,omitempty"`                // Set when the user is signed in
+	Locale                 string     `json:"locale,omitempty"`                 // The locale of the user, such as en-US
+	LastSeen               *time.Time `json:"lastSeen,omitempty"`               // Last time the user interacted with the app, nil for new users
+	UserStorage            string     `json:"userStorage,omitempty"`            // Opaque data persisted across conversations
+	UserVerificationStatus string     `json:"userVerificationStatus,omitempty"` // VERIFIED or GUEST
+	Permissions            []string   `json:"permissions,omitempty"`            // Permissions granted by the user
+}
+
+// IsReturning returns true if the user already interacted with the app
+func (u GoogleUser) IsReturning() bool {
+	return u.LastSeen != nil && !u.LastSeen.IsZero()
+}
+
+// IsVerified returns true if the user is verified, which means the user
+// storage can be used
+func (u GoogleUser) IsVerified() bool {
+	return u.UserVerificationStatus == UserVerified
+}
+
+// GetStorage unmarshals the user storage, which is expected to contain JSON,
+// to the given struct
+func (u GoogleUser) GetStorage(i interface{}) error {
+	return json.Unmarshal([]byte(u.UserStorage), &i)
+}
+
+// GoogleConversation describes the conversation
+type GoogleConversation struct {
+	ConversationID    string `json:"conversationId,omitempty"`
+	Type              string `json:"type,omitempty"` // NEW or ACTIVE
+	ConversationToken string `json:"conversationToken,omitempty"`
+}
+
+// IsNew returns true if this is the first turn of the conversation
+func (c GoogleConversation) IsNew() bool {
+	return c.Type == ConversationNew
+}
+
+// GoogleInput is an input of the user
+type GoogleInput struct {
+	Intent    string           `json:"intent,omitempty"`
+	RawInputs []GoogleRawInput `json:"rawInputs,omitempty"`
+	Arguments []GoogleArgument `json:"arguments,omitempty"`
+}
+
+// GoogleRawInput is what the user actually said or typed
+type GoogleRawInput struct {
+	InputType string `json:"inputType,omitempty"` // VOICE, KEYBOARD, TOUCH…
+	Query     string `json:"query,omitempty"`
+}
+
+// GoogleArgument is an argument of an input, such as the option the user
+// selected in a list
+type GoogleArgument struct {
+	Name      string          `json:"name,omitempty"`
+	RawText   string          `json:"rawText,omitempty"`
+	TextValue string          `json:"textValue,omitempty"`
+	BoolValue bool            `json:"boolValue,omitempty"`
+	Extension json.RawMessage `json:"extension,omitempty"`
+}
+
+// Surface describes a device the user can interact with
+type Surface struct {
+	Capabilities []Capability `json:"capabilities,omitempty"`
+}
+
+// HasCapability returns true if the surface has the capability with the
+// given name, such as actions.capability.SCREEN_OUTPUT
+func (s Surface) HasCapability(name string) bool {
+	for _, c := range s.Capabilities {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Capability is a capability of a surface
+type Capability struct {
+	Name string `json:"name,omitempty"`
+}
+
+// GooglePayload returns the Actions on Google payload of the original
+// request. ErrNotGoogle is returned if the request comes from another source,
+// and an error if the decoder registered for the "google" source doesn't
+// return a GooglePayload.
+func (rw *Request) GooglePayload() (*GooglePayload, error) {
+	if rw.OriginalDetectIntentRequest.Platform() != ActionsOnGoogle {
+		return nil, ErrNotGoogle
+	}
+	p, err := rw.OriginalDetectIntentRequest.DecodePayload()
+	if err != nil {
+		return nil, err
+	}
+	switch gp := p.(type) {
+	case *GooglePayload:
+		return gp, nil
+	case GooglePayload:
+		return &gp, nil
+	}
+	return nil, fmt.Errorf("dialogflow: unexpected Actions on Google payload type %T", p)
+}
@@ -0,0 +1,130 @@
+package dialogflow
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const googleRequest = `{
+	"session": "projects/p/agent/sessions/ABwppHE",
+	"queryResult": {"queryText": "GOOGLE_ASSISTANT_WELCOME"},
+	"originalDetectIntentRequest": {
+		"source": "google",
+		"version": "2",
+		"payload": {
+			"isInSandbox": true,
+			"surface": {"capabilities": [
+				{"name": "actions.capability.MEDIA_RESPONSE_AUDIO"},
+				{"name": "actions.capability.SCREEN_OUTPUT"},
+				{"name": "actions.capability.AUDIO_OUTPUT"},
+				{"name": "actions.capability.WEB_BROWSER"}
+			]},
+			"requestType": "SIMULATOR",
+			"inputs": [{
+				"rawInputs": [{"query": "Talk to my test app", "inputType": "KEYBOARD"}],
+				"arguments": [{"rawText": "Talk to my test app", "textValue": "Talk to my test app", "name": "trigger_query"}],
+				"intent": "actions.intent.MAIN"
+			}],
+			"user": {
+				"lastSeen": "2018-10-01T09:30:00Z",
+				"locale": "en-US",
+				"userStorage": "{\"data\":{\"visits\":3}}",
+				"userVerificationStatus": "VERIFIED"
+			},
+			"conversation": {"conversationId": "ABwppHE", "type": "NEW"},
+			"availableSurfaces": [{"capabilities": [
+				{"name": "actions.capability.AUDIO_OUTPUT"},
+				{"name": "actions.capability.SCREEN_OUTPUT"},
+				{"name": "actions.capability.WEB_BROWSER"}
+			]}]
+		}
+	}
+}`
+
+func TestRequest_GooglePayload(t *testing.T) {
+	var rw Request
+	if err := json.Unmarshal([]byte(googleRequest), &rw); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	p, err := rw.GooglePayload()
+	if err != nil {
+		t.Fatalf("Request.GooglePayload() error = %v", err)
+	}
+
+	assert.True(t, p.IsInSandbox)
+	assert.Equal(t, "SIMULATOR", p.RequestType)
+
+	assert.Equal(t, "en-US", p.User.Locale)
+	assert.True(t, p.User.IsReturning())
+	assert.True(t, p.User.IsVerified())
+	assert.Equal(t, time.Date(2018, 10, 1, 9, 30, 0, 0, time.UTC), *p.User.LastSeen)
+	var storage struct {
+		Data struct {
+			Visits int `json:"visits"`
+		} `json:"data"`
+	}
+	assert.NoError(t, p.User.GetStorage(&storage))
+	assert.Equal(t, 3, storage.Data.Visits)
+
+	assert.True(t, p.Conversation.IsNew())
+	assert.Equal(t, "ABwppHE", p.Conversation.ConversationID)
+
+	if assert.Len(t, p.Inputs, 1) {
+		assert.Equal(t, "actions.intent.MAIN", p.Inputs[0].Intent)
+		assert.Equal(t, []GoogleRawInput{{InputType: "KEYBOARD", Query: "Talk to my test app"}}, p.Inputs[0].RawInputs)
+		assert.Equal(t, "trigger_query", p.Inputs[0].Arguments[0].Name)
+	}
+
+	assert.True(t, p.Surface.HasCapability("actions.capability.SCREEN_OUTPUT"))
+	assert.False(t, p.Surface.HasCapability("actions.capability.ACCOUNT_LINKING"))
+	if assert.Len(t, p.AvailableSurfaces, 1) {
+		assert.True(t, p.AvailableSurfaces[0].HasCapability("actions.capability.WEB_BROWSER"))
+	}
+}
+
+func TestRequest_GooglePayload_Errors(t *testing.T) {
+	rw := &Request{OriginalDetectIntentRequest: OriginalDetectIntentRequest{Source: "slack"}}
+	_, err := rw.GooglePayload()
+	assert.Equal(t, ErrNotGoogle, err)
+
+	rw.OriginalDetectIntentRequest = OriginalDetectIntentRequest{Source: "google", Payload: []byte(`{"user": []}`)}
+	_, err = rw.GooglePayload()
+	assert.Error(t, err)
+}
+
+func TestRequest_GooglePayload_CustomDecoder(t *testing.T) {
+	payloadDecoders.RLock()
+	def := payloadDecoders.decoders["google"]
+	payloadDecoders.RUnlock()
+	defer RegisterPayloadDecoder("google", def)
+
+	rw := &Request{OriginalDetectIntentRequest: OriginalDetectIntentRequest{Source: "Google", Payload: []byte(`{"isInSandbox": true}`)}}
+	p, err := rw.GooglePayload()
+	assert.NoError(t, err, "sources should be case insensitive")
+	assert.True(t, p.IsInSandbox)
+
+	RegisterPayloadDecoder("google", func(raw json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{}, nil
+	})
+	_, err = rw.GooglePayload()
+	assert.Error(t, err)
+	assert.False(t, rw.HasScreen(), "should not panic with an unexpected payload type")
+
+	RegisterPayloadDecoder("google", func(raw json.RawMessage) (interface{}, error) {
+		return GooglePayload{IsInSandbox: true}, nil
+	})
+	p, err = rw.GooglePayload()
+	assert.NoError(t, err)
+	assert.True(t, p.IsInSandbox)
+}
+
+func TestGoogleUser(t *testing.T) {
+	u := GoogleUser{UserVerificationStatus: UserGuest}
+	assert.False(t, u.IsReturning())
+	assert.False(t, u.IsVerified())
+	assert.Error(t, u.GetStorage(&struct{}{}), "empty storage isn't valid JSON")
+	assert.False(t, GoogleConversation{Type: ConversationActive}.IsNew())
+}
@@ -0,0 +1,122 @@
+package dialogflow
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/leboncoin/dialogflow-go-webhook/internal/webhook"
+)
+
+// HandlerFunc is the function signature used to handle a dialogflow request
+// and build the fulfillment that will be sent back. The request is also
+// available in the context, see RequestFromContext.
+type HandlerFunc func(ctx context.Context, req *Request) (*Fulfillment, error)
+
+// ServeHTTP implements the http.Handler interface. The request body is
+// decoded to a Request, given to the handler, and the returned fulfillment is
+// encoded in the response. If the handler returns an error, DefaultFallback
+// is used to build the response, see WithFallback to use another one. The
+// request is decoded using DecodeRequest with the default options.
+func (h HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	WithFallback(h, DefaultFallback).ServeHTTP(w, r)
+}
+
+// WithFallback returns an http.Handler serving the handler like
+// HandlerFunc.ServeHTTP, but using fb to build the response when the handler
+// returns an error. A Router can be used instead for more options.
+func WithFallback(h HandlerFunc, fb FallbackFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serve(w, r, DecodeOptions{}, nil, withFallback(h, fb))
+	})
+}
+
+// withFallback wraps the handler so that the errors it returns are turned
+// into a fulfillment using fb
+func withFallback(h HandlerFunc, fb FallbackFunc) HandlerFunc {
+	return func(ctx context.Context, req *Request) (*Fulfillment, error) {
+		dff, err := h(ctx, req)
+		if err != nil {
+			return fb(ctx, req, err), nil
+		}
+		return dff, nil
+	}
+}
+
+// FallbackFunc builds the fulfillment sent back to dialogflow when a handler
+// returns an error. Dialogflow replaces any non 200 response with a generic
+// message, so answering with a proper fulfillment is usually a better idea.
+type FallbackFunc func(ctx context.Context, req *Request, err error) *Fulfillment
+
+// DefaultFallback is the fallback used when none is configured. It returns an
+// empty fulfillment, which makes dialogflow use the responses defined in the
+// intent itself.
+func DefaultFallback(ctx context.Context, req *Request, err error) *Fulfillment {
+	return &Fulfillment{}
+}
+
+// FallbackFulfillment returns a FallbackFunc that always responds with the
+// given fulfillment, regardless of the error
+func FallbackFulfillment(f *Fulfillment) FallbackFunc {
+	return func(ctx context.Context, req *Request, err error) *Fulfillment {
+		return f
+	}
+}
+
+// contextKey is the type of the keys used to store values in a
+// context.Context, unexported to avoid collisions
+type contextKey int
+
+const requestKey contextKey = iota
+
+// WithRequest returns a copy of the context holding the given request. This
+// is done automatically by the Router and HandlerFunc, but can be useful to
+// call handlers directly, for example in tests.
+func WithRequest(ctx context.Context, req *Request) context.Context {
+	return context.WithValue(ctx, requestKey, req)
+}
+
+// RequestFromContext returns the dialogflow request stored in the context, or
+// nil if there is none
+func RequestFromContext(ctx context.Context) *Request {
+	req, _ := ctx.Value(requestKey).(*Request)
+	return req
+}
+
+// SessionFromContext returns the session of the dialogflow request stored in
+// the context, or an empty string if there is none
+func SessionFromContext(ctx context.Context) string {
+	if req := RequestFromContext(ctx); req != nil {
+		return req.Session
+	}
+	return ""
+}
+
+// LanguageFromContext returns the language code of the dialogflow request
+// stored in the context, or an empty string if there is none
+func LanguageFromContext(ctx context.Context) string {
+	if req := RequestFromContext(ctx); req != nil {
+		return req.QueryResult.LanguageCode
+	}
+	return ""
+}
+
+// serve decodes the incoming request using DecodeRequest, hands it to the
+// handler along with a context holding it, and encodes the returned
+// fulfillment. Requests that can't be decoded are logged using l if not nil.
+// ErrNoHandler and ErrUnknownAgent are answered with a 404 status code, any
+// other error with a 500.
+func serve(w http.ResponseWriter, r *http.Request, opts DecodeOptions, l *log.Logger, h HandlerFunc) {
+	decode := func(r *http.Request) (interface{}, error) {
+		return DecodeRequest(r, opts)
+	}
+	handle := func(ctx context.Context, req interface{}) (interface{}, error) {
+		dfr := req.(*Request)
+		dff, err := h(WithRequest(ctx, dfr), dfr)
+		if err == nil && dff == nil {
+			dff = &Fulfillment{}
+		}
+		return dff, err
+	}
+	webhook.Serve(w, r, l, decode, handle, ErrNoHandler, ErrUnknownAgent)
+}
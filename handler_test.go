@@ -0,0 +1,81 @@
+package dialogflow
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextAccessors(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, RequestFromContext(ctx))
+	assert.Equal(t, "", SessionFromContext(ctx))
+	assert.Equal(t, "", LanguageFromContext(ctx))
+
+	req := &Request{Session: "projects/p/agent/sessions/s", QueryResult: QueryResult{LanguageCode: "fr"}}
+	ctx = WithRequest(ctx, req)
+	assert.Equal(t, req, RequestFromContext(ctx))
+	assert.Equal(t, "projects/p/agent/sessions/s", SessionFromContext(ctx))
+	assert.Equal(t, "fr", LanguageFromContext(ctx))
+}
+
+func TestHandlerFunc_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler HandlerFunc
+		status  int
+		want    string
+	}{
+		{
+			"should respond with the request values",
+			func(ctx context.Context, req *Request) (*Fulfillment, error) {
+				return &Fulfillment{FulfillmentText: SessionFromContext(ctx) + " " + LanguageFromContext(ctx)}, nil
+			},
+			http.StatusOK,
+			`{"fulfillmentText": "session en", "followupEventInput": {"name": ""}}`,
+		},
+		{
+			"should use the default fallback",
+			func(ctx context.Context, req *Request) (*Fulfillment, error) {
+				return nil, errors.New("broken")
+			},
+			http.StatusOK,
+			`{"followupEventInput": {"name": ""}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			body := strings.NewReader(`{"session": "session", "queryResult": {"languageCode": "en"}}`)
+			tt.handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook", body))
+			assert.Equal(t, tt.status, w.Code)
+			if err := JSONStringsEqual(w.Body.String(), tt.want); err != nil {
+				t.Errorf("HandlerFunc.ServeHTTP() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestWithFallback(t *testing.T) {
+	h := func(ctx context.Context, req *Request) (*Fulfillment, error) {
+		return nil, errors.New("broken")
+	}
+	w := httptest.NewRecorder()
+	body := strings.NewReader(`{"session": "session", "queryResult": {"languageCode": "en"}}`)
+	WithFallback(h, FallbackFulfillment(&Fulfillment{FulfillmentText: "sorry"})).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook", body))
+	assert.Equal(t, http.StatusOK, w.Code)
+	if err := JSONStringsEqual(w.Body.String(), `{"fulfillmentText": "sorry", "followupEventInput": {"name": ""}}`); err != nil {
+		t.Errorf("WithFallback() error = %v", err)
+	}
+}
+
+func TestFallbackFulfillment(t *testing.T) {
+	f := &Fulfillment{FulfillmentText: "sorry"}
+	got := FallbackFulfillment(f)(context.Background(), &Request{}, errors.New("broken"))
+	assert.Equal(t, f, got)
+}
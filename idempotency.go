@@ -0,0 +1,146 @@
+package dialogflow
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// FulfillmentStore stores fulfillments for a limited amount of time. It is
+// used by the Idempotent middleware and must be safe for concurrent use.
+type FulfillmentStore interface {
+	// Get returns the fulfillment stored for the key. The boolean is false if
+	// there is none or if it expired.
+	Get(key string) (*Fulfillment, bool, error)
+	// Set stores the fulfillment for the key during ttl
+	Set(key string, f *Fulfillment, ttl time.Duration) error
+}
+
+// MemoryStore is an in-memory implementation of the FulfillmentStore
+// interface
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	swept   time.Time
+	now     func() time.Time
+}
+
+type memoryEntry struct {
+	f       *Fulfillment
+	expires time.Time
+}
+
+// NewMemoryStore returns a new empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]memoryEntry),
+		now:     time.Now,
+	}
+}
+
+// Get implements the FulfillmentStore interface. The returned fulfillment is
+// a copy of the stored one.
+func (m *MemoryStore) Get(key string) (*Fulfillment, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok || !m.now().Before(e.expires) {
+		return nil, false, nil
+	}
+	return copyFulfillment(e.f), true, nil
+}
+
+// Set implements the FulfillmentStore interface. A copy of the fulfillment is
+// stored. Expired entries are removed at most once per minute when calling
+// Set.
+func (m *MemoryStore) Set(key string, f *Fulfillment, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	if now.Sub(m.swept) > time.Minute {
+		for k, e := range m.entries {
+			if !now.Before(e.expires) {
+				delete(m.entries, k)
+			}
+		}
+		m.swept = now
+	}
+	m.entries[key] = memoryEntry{f: copyFulfillment(f), expires: now.Add(ttl)}
+	return nil
+}
+
+// Idempotent returns a middleware that makes sure a request is only handled
+// once, even when dialogflow delivers it several times. Requests are
+// identified by their ResponseID, and the fulfillment returned by the
+// handler is kept in the store during ttl. Duplicates that arrive while the
+// first request is still being handled wait for its result, and get a
+// *PanicError if the handler panics.
+// Errors returned by the handler aren't cached, and store failures don't
+// prevent the request from being handled: the handler is called if the
+// fulfillment can't be retrieved, and its result is sent back even if it
+// can't be stored.
+func Idempotent(store FulfillmentStore, ttl time.Duration) Middleware {
+	type call struct {
+		done chan struct{}
+		dff  *Fulfillment
+		err  error
+	}
+	var mu sync.Mutex
+	inflight := make(map[string]*call)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) (*Fulfillment, error) {
+			key := req.ResponseID
+			if key == "" {
+				return next(ctx, req)
+			}
+
+			mu.Lock()
+			if c, ok := inflight[key]; ok {
+				mu.Unlock()
+				select {
+				case <-c.done:
+					if c.dff == nil {
+						return nil, c.err
+					}
+					// Copy so that concurrent responses don't share the same value
+					return copyFulfillment(c.dff), c.err
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+			c := &call{done: make(chan struct{})}
+			inflight[key] = c
+			mu.Unlock()
+			defer func() {
+				// Let the duplicates know the handler panicked instead of
+				// answering them with an empty result
+				r := recover()
+				if r != nil {
+					c.dff, c.err = nil, &PanicError{Value: r, Stack: debug.Stack()}
+				}
+				mu.Lock()
+				delete(inflight, key)
+				mu.Unlock()
+				close(c.done)
+				if r != nil {
+					panic(r)
+				}
+			}()
+
+			// A failing store must not prevent the request from being handled
+			dff, ok, err := store.Get(key)
+			if err != nil || !ok {
+				if dff, err = next(ctx, req); err == nil && dff != nil {
+					store.Set(key, dff, ttl)
+				}
+			}
+			c.err = err
+			if dff != nil {
+				c.dff = copyFulfillment(dff)
+			}
+			return dff, err
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package dialogflow
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+	m := NewMemoryStore()
+	m.now = func() time.Time { return now }
+
+	_, ok, err := m.Get("key")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	f := &Fulfillment{FulfillmentText: "hello"}
+	assert.NoError(t, m.Set("key", f, time.Minute))
+	got, ok, err := m.Get("key")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, f, got)
+	got.FulfillmentText = "modified"
+	got, _, _ = m.Get("key")
+	assert.Equal(t, "hello", got.FulfillmentText, "stored value should not be modified")
+
+	now = now.Add(time.Minute)
+	_, ok, _ = m.Get("key")
+	assert.False(t, ok, "should expire")
+
+	now = now.Add(time.Hour)
+	assert.NoError(t, m.Set("other", f, time.Minute))
+	assert.Len(t, m.entries, 1, "expired entries should be removed")
+}
+
+func TestIdempotent(t *testing.T) {
+	var calls int32
+	h := func(ctx context.Context, req *Request) (*Fulfillment, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if req.QueryResult.Action == "fail" {
+			return nil, errors.New("broken")
+		}
+		if n > 1 && req.QueryResult.Action != "" {
+			return &Fulfillment{FulfillmentText: "again"}, nil
+		}
+		return &Fulfillment{FulfillmentText: "once"}, nil
+	}
+	run := func(m Middleware, req *Request) (*Fulfillment, error) {
+		return m(h)(context.Background(), req)
+	}
+
+	t.Run("should return cached fulfillment", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		m := Idempotent(NewMemoryStore(), time.Minute)
+		req := &Request{ResponseID: "1234", QueryResult: QueryResult{Action: "create"}}
+		for i := 0; i < 3; i++ {
+			got, err := run(m, req)
+			assert.NoError(t, err)
+			assert.Equal(t, "once", got.FulfillmentText)
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("should not cache without response id", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		m := Idempotent(NewMemoryStore(), time.Minute)
+		req := &Request{QueryResult: QueryResult{Action: "create"}}
+		run(m, req)
+		got, _ := run(m, req)
+		assert.Equal(t, "again", got.FulfillmentText)
+	})
+
+	t.Run("should not cache errors", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		m := Idempotent(NewMemoryStore(), time.Minute)
+		req := &Request{ResponseID: "1234", QueryResult: QueryResult{Action: "fail"}}
+		_, err := run(m, req)
+		assert.Error(t, err)
+		_, err = run(m, req)
+		assert.Error(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("should wait for in-flight duplicates", func(t *testing.T) {
+		var handled int32
+		release := make(chan struct{})
+		slow := func(ctx context.Context, req *Request) (*Fulfillment, error) {
+			atomic.AddInt32(&handled, 1)
+			<-release
+			return &Fulfillment{FulfillmentText: "slow"}, nil
+		}
+		hf := Idempotent(NewMemoryStore(), time.Minute)(slow)
+		req := &Request{ResponseID: "1234"}
+
+		var wg sync.WaitGroup
+		results := make([]*Fulfillment, 5)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], _ = hf(context.Background(), req)
+			}(i)
+		}
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		assert.Equal(t, int32(1), atomic.LoadInt32(&handled))
+		for _, r := range results {
+			assert.Equal(t, "slow", r.FulfillmentText)
+		}
+	})
+}
+
+// failingStore is a FulfillmentStore whose operations always fail
+type failingStore struct{}
+
+func (failingStore) Get(key string) (*Fulfillment, bool, error) {
+	return nil, false, errors.New("store unavailable")
+}
+
+func (failingStore) Set(key string, f *Fulfillment, ttl time.Duration) error {
+	return errors.New("store unavailable")
+}
+
+func TestIdempotent_FailingStore(t *testing.T) {
+	var handled int32
+	h := func(ctx context.Context, req *Request) (*Fulfillment, error) {
+		atomic.AddInt32(&handled, 1)
+		return &Fulfillment{FulfillmentText: "hello"}, nil
+	}
+	hf := Idempotent(failingStore{}, time.Minute)(h)
+	req := &Request{ResponseID: "1234"}
+
+	for i := 0; i < 2; i++ {
+		got, err := hf(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", got.FulfillmentText)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&handled), "handler should run when the store can't be read")
+}
+
+func TestIdempotent_DeepCopy(t *testing.T) {
+	h := func(ctx context.Context, req *Request) (*Fulfillment, error) {
+		return &Fulfillment{
+			FulfillmentMessages: Messages{{RichMessage: Text{Text: []string{"hello"}}}},
+			OutputContexts:      Contexts{{Name: "s/contexts/order", LifespanCount: 3}},
+		}, nil
+	}
+	bump := After(func(ctx context.Context, req *Request, dff *Fulfillment) (*Fulfillment, error) {
+		dff.OutputContexts[0].LifespanCount++
+		dff.FulfillmentMessages[0] = Message{}
+		return dff, nil
+	})
+	hf := Chain(h, bump, Idempotent(NewMemoryStore(), time.Minute))
+	req := &Request{ResponseID: "1234"}
+
+	for i := 0; i < 2; i++ {
+		got, err := hf(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, got.OutputContexts[0].LifespanCount, "cached contexts should not be modified")
+	}
+
+	m := NewMemoryStore()
+	f := &Fulfillment{OutputContexts: Contexts{{Name: "s/contexts/order", LifespanCount: 3}}}
+	assert.NoError(t, m.Set("key", f, time.Minute))
+	f.OutputContexts[0].LifespanCount = 10
+	got, _, _ := m.Get("key")
+	assert.Equal(t, 3, got.OutputContexts[0].LifespanCount, "stored contexts should be copied")
+}
+
+func TestIdempotent_Panic(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	h := func(ctx context.Context, req *Request) (*Fulfillment, error) {
+		close(entered)
+		<-release
+		panic("boom")
+	}
+	hf := Idempotent(NewMemoryStore(), time.Minute)(h)
+	req := &Request{ResponseID: "1234"}
+
+	panicked := make(chan interface{})
+	go func() {
+		defer func() { panicked <- recover() }()
+		hf(context.Background(), req)
+	}()
+	<-entered
+
+	type result struct {
+		dff *Fulfillment
+		err error
+	}
+	waiter := make(chan result)
+	go func() {
+		dff, err := hf(context.Background(), req)
+		waiter <- result{dff, err}
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	assert.Equal(t, "boom", <-panicked, "the panic should be propagated")
+	r := <-waiter
+	assert.Nil(t, r.dff)
+	var perr *PanicError
+	if assert.True(t, errors.As(r.err, &perr), "duplicates should get a PanicError") {
+		assert.Equal(t, "boom", perr.Value)
+	}
+}
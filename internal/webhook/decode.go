@@ -0,0 +1,141 @@
+// Package webhook holds the HTTP plumbing shared by the Dialogflow ES and CX
+// webhooks: decoding of the request bodies and serving of the responses.
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxBodySize is the maximum body size used by DecodeJSON when none is
+// given
+const DefaultMaxBodySize = 1 << 20
+
+// Errors describing why a request couldn't be decoded. They can be checked
+// against a DecodeError using errors.Is.
+var (
+	ErrEmptyBody     = errors.New("empty body")
+	ErrBodyTooLarge  = errors.New("body too large")
+	ErrMalformedJSON = errors.New("malformed json")
+	ErrUnknownField  = errors.New("unknown field")
+	ErrMissingField  = errors.New("missing required field")
+)
+
+// DecodeError is returned by DecodeJSON when the request can't be decoded
+type DecodeError struct {
+	Reason error  // One of the Err* variables describing the problem
+	Field  string // The field at fault, if any
+	Offset int64  // Offset in the body where the problem occurred, if known
+	Err    error  // The underlying error, if any
+}
+
+// Error implements the error interface
+func (e *DecodeError) Error() string {
+	msg := "dialogflow: invalid request: " + e.Reason.Error()
+	if e.Field != "" {
+		msg += fmt.Sprintf(" %q", e.Field)
+	}
+	if e.Offset > 0 {
+		msg += fmt.Sprintf(" at offset %d", e.Offset)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the reason of the error, so that errors.Is can be used
+func (e *DecodeError) Unwrap() error {
+	return e.Reason
+}
+
+// DecodeOptions configures the behaviour of DecodeJSON
+type DecodeOptions struct {
+	// MaxBodySize is the maximum size of the body in bytes.
+	// DefaultMaxBodySize is used if zero.
+	MaxBodySize int64
+	// DisallowUnknownFields rejects requests containing fields that aren't
+	// part of the decoded type
+	DisallowUnknownFields bool
+}
+
+// DecodeJSON reads and decodes the body of the HTTP request to v. The given
+// top level fields must be present and neither null nor empty. A
+// *DecodeError is returned if anything goes wrong.
+func DecodeJSON(r *http.Request, v interface{}, opts DecodeOptions, required ...string) error {
+	max := opts.MaxBodySize
+	if max <= 0 {
+		max = DefaultMaxBodySize
+	}
+	if r.Body == nil {
+		return &DecodeError{Reason: ErrEmptyBody}
+	}
+	defer r.Body.Close()
+
+	// Read one more byte than allowed to detect bodies that are too large
+	b, err := ioutil.ReadAll(io.LimitReader(r.Body, max+1))
+	if err != nil {
+		return &DecodeError{Reason: ErrMalformedJSON, Err: err}
+	}
+	if int64(len(b)) > max {
+		return &DecodeError{Reason: ErrBodyTooLarge, Err: fmt.Errorf("limit is %d bytes", max)}
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return &DecodeError{Reason: ErrEmptyBody}
+	}
+
+	br := bytes.NewReader(b)
+	d := json.NewDecoder(br)
+	if opts.DisallowUnknownFields {
+		d.DisallowUnknownFields()
+	}
+	if err = d.Decode(v); err != nil {
+		return decodeError(err)
+	}
+	if d.More() {
+		// The data left is what the decoder buffered plus what it didn't read
+		rest, _ := ioutil.ReadAll(d.Buffered())
+		offset := int64(len(b) - len(rest) - br.Len())
+		return &DecodeError{Reason: ErrMalformedJSON, Offset: offset, Err: errors.New("unexpected data after the request")}
+	}
+	if len(required) == 0 {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(b, &fields); err != nil {
+		return decodeError(err)
+	}
+	for _, f := range required {
+		if v, ok := fields[f]; !ok || string(v) == "null" || string(v) == `""` {
+			return &DecodeError{Reason: ErrMissingField, Field: f}
+		}
+	}
+	return nil
+}
+
+// decodeError converts an error returned by the json package to a
+// *DecodeError
+func decodeError(err error) *DecodeError {
+	var syntax *json.SyntaxError
+	var typ *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntax):
+		return &DecodeError{Reason: ErrMalformedJSON, Offset: syntax.Offset, Err: err}
+	case errors.As(err, &typ):
+		return &DecodeError{Reason: ErrMalformedJSON, Field: typ.Field, Offset: typ.Offset, Err: err}
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		return &DecodeError{Reason: ErrMalformedJSON, Err: io.ErrUnexpectedEOF}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		f := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return &DecodeError{Reason: ErrUnknownField, Field: f}
+	}
+	return &DecodeError{Reason: ErrMalformedJSON, Err: err}
+}
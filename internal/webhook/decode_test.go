@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	type payload struct {
+		Tag string `json:"tag"`
+	}
+	tests := []struct {
+		name     string
+		body     string
+		required []string
+		want     payload
+		reason   error
+	}{
+		{"should decode without required fields", `{"tag": "t"}`, nil, payload{Tag: "t"}, nil},
+		{"should decode with required fields", `{"tag": "t"}`, []string{"tag"}, payload{Tag: "t"}, nil},
+		{"should require fields", `{"other": "t"}`, []string{"tag"}, payload{}, ErrMissingField},
+		{"should reject malformed json", `{"tag": `, nil, payload{}, ErrMalformedJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got payload
+			r := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(tt.body))
+			err := DecodeJSON(r, &got, DecodeOptions{}, tt.required...)
+			if tt.reason != nil {
+				assert.True(t, errors.Is(err, tt.reason), "expected %v, got %v", tt.reason, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDecodeJSON_TrailingData(t *testing.T) {
+	var v map[string]interface{}
+	r := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"a": 1} {}`))
+	err := DecodeJSON(r, &v, DecodeOptions{})
+	var derr *DecodeError
+	if assert.True(t, errors.As(err, &derr)) {
+		assert.Equal(t, ErrMalformedJSON, derr.Reason)
+		assert.Equal(t, int64(8), derr.Offset)
+	}
+}
@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// Serve answers non POST requests with a 405 status code, decodes the body
+// using decode and gives the result to the handler. Decoding errors are
+// logged using l if not nil and answered with a 413 status code for
+// ErrBodyTooLarge, a 400 otherwise. Handler errors matching one of notFound
+// are answered with a 404 status code, any other error with a 500. The
+// response returned by the handler is encoded as JSON.
+func Serve(w http.ResponseWriter, r *http.Request, l *log.Logger, decode func(r *http.Request) (interface{}, error), h func(ctx context.Context, req interface{}) (interface{}, error), notFound ...error) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	req, err := decode(r)
+	if err != nil {
+		if l != nil {
+			l.Printf("%s: %v", r.RemoteAddr, err)
+		}
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrBodyTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	res, err := h(r.Context(), req)
+	if err != nil {
+		for _, nf := range notFound {
+			if errors.Is(err, nf) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+		}
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServe(t *testing.T) {
+	type request struct {
+		Text string `json:"text"`
+	}
+	type response struct {
+		Echo string `json:"echo,omitempty"`
+	}
+	errNotFound := errors.New("not found")
+	decode := func(r *http.Request) (interface{}, error) {
+		var req request
+		if err := DecodeJSON(r, &req, DecodeOptions{MaxBodySize: 100}, "text"); err != nil {
+			return nil, err
+		}
+		return &req, nil
+	}
+	h := func(ctx context.Context, req interface{}) (interface{}, error) {
+		switch text := req.(*request).Text; text {
+		case "unknown":
+			return nil, fmt.Errorf("%w: %q", errNotFound, text)
+		case "broken":
+			return nil, errors.New("broken")
+		default:
+			return &response{Echo: text}, nil
+		}
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		status int
+		want   string
+	}{
+		{"should respond", http.MethodPost, `{"text": "hello"}`, http.StatusOK, `{"echo": "hello"}`},
+		{"should reject other methods", http.MethodGet, ``, http.StatusMethodNotAllowed, ""},
+		{"should reject invalid body", http.MethodPost, `{}`, http.StatusBadRequest, ""},
+		{"should reject large body", http.MethodPost, `{"text": "` + strings.Repeat("a", 100) + `"}`, http.StatusRequestEntityTooLarge, ""},
+		{"should not find", http.MethodPost, `{"text": "unknown"}`, http.StatusNotFound, ""},
+		{"should fail on handler error", http.MethodPost, `{"text": "broken"}`, http.StatusInternalServerError, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			Serve(w, httptest.NewRequest(tt.method, "/webhook", strings.NewReader(tt.body)), nil, decode, h, errNotFound)
+			assert.Equal(t, tt.status, w.Code)
+			if tt.want != "" {
+				assert.JSONEq(t, tt.want, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package dialogflow
+
+// Match confidence levels of knowledge answers
+const (
+	ConfidenceLow    = "LOW"
+	ConfidenceMedium = "MEDIUM"
+	ConfidenceHigh   = "HIGH"
+)
+
+// KnowledgeAnswers holds the answers found by the knowledge connectors
+type KnowledgeAnswers struct {
+	Answers []KnowledgeAnswer `json:"answers,omitempty"`
+}
+
+// KnowledgeAnswer is an answer found in a knowledge base
+type KnowledgeAnswer struct {
+	Source               string  `json:"source,omitempty"`               // The knowledge document the answer comes from
+	FaqQuestion          string  `json:"faqQuestion,omitempty"`          // The matched question, for FAQ documents
+	Answer               string  `json:"answer,omitempty"`               // The answer itself
+	MatchConfidenceLevel string  `json:"matchConfidenceLevel,omitempty"` // LOW, MEDIUM or HIGH
+	MatchConfidence      float64 `json:"matchConfidence,omitempty"`      // From 0.0 (uncertain) to 1.0 (certain)
+}
+
+// SimpleResponse wraps the answer in a simple response, used both as text
+// and speech
+func (a KnowledgeAnswer) SimpleResponse() SimpleResponsesWrapper {
+	return SingleSimpleResponse(a.Answer, a.Answer)
+}
+
+// Text wraps the answer in a text message
+func (a KnowledgeAnswer) Text() Text {
+	return Text{Text: []string{a.Answer}}
+}
+
+// BestKnowledgeAnswer returns the knowledge answer with the highest match
+// confidence, among the answers of the query result and of the alternative
+// query results. The boolean is false if no answer has a confidence greater
+// than or equal to threshold.
+func (rw *Request) BestKnowledgeAnswer(threshold float64) (KnowledgeAnswer, bool) {
+	var best KnowledgeAnswer
+	found := false
+	results := append([]QueryResult{rw.QueryResult}, rw.AlternativeQueryResults...)
+	for _, qr := range results {
+		if qr.KnowledgeAnswers == nil {
+			continue
+		}
+		for _, a := range qr.KnowledgeAnswers.Answers {
+			if a.MatchConfidence >= threshold && (!found || a.MatchConfidence > best.MatchConfidence) {
+				best, found = a, true
+			}
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,79 @@
+package dialogflow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const knowledgeRequest = `{
+	"session": "projects/p/agent/sessions/s",
+	"responseId": "1234",
+	"queryResult": {
+		"queryText": "how do I post an ad",
+		"languageCode": "en",
+		"intent": {"name": "projects/p/agent/intents/fallback", "displayName": "Default Fallback Intent", "isFallback": true},
+		"knowledgeAnswers": {"answers": [
+			{"source": "projects/p/knowledgeBases/kb/documents/faq", "faqQuestion": "How to post an ad?", "answer": "Click on the post button.", "matchConfidenceLevel": "MEDIUM", "matchConfidence": 0.6}
+		]}
+	},
+	"alternativeQueryResults": [{
+		"queryText": "how do I post an ad",
+		"languageCode": "en",
+		"intent": {"name": "projects/p/agent/intents/kb", "displayName": "Knowledge.KnowledgeBase.a2I"},
+		"knowledgeAnswers": {"answers": [
+			{"source": "projects/p/knowledgeBases/kb/documents/faq", "faqQuestion": "How do I post an ad?", "answer": "Use the post an ad button at the top of the page.", "matchConfidenceLevel": "HIGH", "matchConfidence": 0.92},
+			{"source": "projects/p/knowledgeBases/kb/documents/faq", "faqQuestion": "How do I edit an ad?", "answer": "Go to your ads.", "matchConfidenceLevel": "LOW", "matchConfidence": 0.2}
+		]}
+	}],
+	"originalDetectIntentRequest": {"payload": {}}
+}`
+
+func TestRequest_KnowledgeAnswers(t *testing.T) {
+	var rw Request
+	if err := json.Unmarshal([]byte(knowledgeRequest), &rw); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if assert.NotNil(t, rw.QueryResult.KnowledgeAnswers) {
+		assert.Equal(t, ConfidenceMedium, rw.QueryResult.KnowledgeAnswers.Answers[0].MatchConfidenceLevel)
+	}
+	if assert.Len(t, rw.AlternativeQueryResults, 1) {
+		assert.Len(t, rw.AlternativeQueryResults[0].KnowledgeAnswers.Answers, 2)
+	}
+	if err := PayloadTester(rw, []byte(knowledgeRequest)); err != nil {
+		t.Errorf("round trip error = %v", err)
+	}
+}
+
+func TestRequest_BestKnowledgeAnswer(t *testing.T) {
+	var rw Request
+	if err := json.Unmarshal([]byte(knowledgeRequest), &rw); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	tests := []struct {
+		name      string
+		req       *Request
+		threshold float64
+		want      string
+		wantOk    bool
+	}{
+		{"should pick the highest confidence", &rw, 0.5, "How do I post an ad?", true},
+		{"should pick with no threshold", &rw, 0, "How do I post an ad?", true},
+		{"should not pick under threshold", &rw, 0.95, "", false},
+		{"should not pick without answers", &Request{}, 0, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.req.BestKnowledgeAnswer(tt.threshold)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got.FaqQuestion)
+		})
+	}
+}
+
+func TestKnowledgeAnswer_Messages(t *testing.T) {
+	a := KnowledgeAnswer{Answer: "Click on the post button."}
+	assert.Equal(t, SingleSimpleResponse("Click on the post button.", "Click on the post button."), a.SimpleResponse())
+	assert.Equal(t, Text{Text: []string{"Click on the post button."}}, a.Text())
+}
@@ -1,15 +1,33 @@
 package dialogflow
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"strings"
+)
 
-// Location is a location object sent back by DialogFlow
+// Location is a location object sent back by DialogFlow, matching either the
+// @sys.location or @sys.address entities
 type Location struct {
-	Simple               string
-	AdminArea            string          `json:"admin-area,omitempty"`
-	AdminAreaOriginal    string          `json:"admin-area.original,omitempty"`
-	AdminAreaObject      json.RawMessage `json:"admin-area.object,omitempty"`
-	SubAdminArea         string          `json:"subadmin-area,omitempty"`
-	SubAdminAreaOriginal string          `json:"subadmin-area.original,omitempty"`
+	Simple                string
+	AdminArea             string          `json:"admin-area,omitempty"`
+	AdminAreaOriginal     string          `json:"admin-area.original,omitempty"`
+	AdminAreaObject       json.RawMessage `json:"admin-area.object,omitempty"`
+	SubAdminArea          string          `json:"subadmin-area,omitempty"`
+	SubAdminAreaOriginal  string          `json:"subadmin-area.original,omitempty"`
+	StreetAddress         string          `json:"street-address,omitempty"`
+	StreetAddressOriginal string          `json:"street-address.original,omitempty"`
+	City                  string          `json:"city,omitempty"`
+	CityOriginal          string          `json:"city.original,omitempty"`
+	Country               string          `json:"country,omitempty"`
+	CountryOriginal       string          `json:"country.original,omitempty"`
+	ZipCode               string          `json:"zip-code,omitempty"`
+	ZipCodeOriginal       string          `json:"zip-code.original,omitempty"`
+	BusinessName          string          `json:"business-name,omitempty"`
+	BusinessNameOriginal  string          `json:"business-name.original,omitempty"`
+	Shortcut              string          `json:"shortcut,omitempty"`
+	ShortcutOriginal      string          `json:"shortcut.original,omitempty"`
+	Island                string          `json:"island,omitempty"`
+	IslandOriginal        string          `json:"island.original,omitempty"`
 }
 
 // UnmarshalJSON implements the Unmarshaler interface for JSON parsing
@@ -32,3 +50,49 @@ func (l *Location) UnmarshalJSON(b []byte) error {
 	}
 	return err
 }
+
+// String formats the location in a human readable way, for example
+// "24 rue Saint-Lazare, 75009 Paris, France"
+func (l Location) String() string {
+	if l.Simple != "" {
+		return l.Simple
+	}
+	city := strings.TrimSpace(l.ZipCode + " " + l.City)
+	var parts []string
+	for _, p := range []string{l.Shortcut, l.BusinessName, l.StreetAddress, city, l.SubAdminArea, l.AdminArea, l.Island, l.Country} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Locations is a list of locations, used for parameters defined as lists
+type Locations []Location
+
+// UnmarshalJSON implements the Unmarshaler interface for JSON parsing
+// This function accepts either a list of locations or a single location, in
+// which case the list will contain a single element.
+func (ls *Locations) UnmarshalJSON(b []byte) error {
+	var l Location
+	var list []Location
+
+	if err := json.Unmarshal(b, &list); err == nil {
+		*ls = list
+		return nil
+	}
+	if err := json.Unmarshal(b, &l); err != nil {
+		return err
+	}
+	*ls = Locations{l}
+	return nil
+}
+
+// String formats the locations, separated by semicolons
+func (ls Locations) String() string {
+	parts := make([]string, len(ls))
+	for i, l := range ls {
+		parts[i] = l.String()
+	}
+	return strings.Join(parts, "; ")
+}
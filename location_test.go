@@ -1,6 +1,7 @@
 package dialogflow
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -18,6 +19,25 @@ func TestLocation_UnmarshalJSON(t *testing.T) {
 	}{
 		{"should unmarshal to location", []byte(`{"subadmin-area": "Paris"}`), &Location{SubAdminArea: "Paris"}, false},
 		{"should unmarshal to simple string", []byte(`"Paris"`), &Location{Simple: "Paris"}, false},
+		{
+			"should unmarshal street address",
+			[]byte(`{"business-name": "", "city": "Paris", "country": "France", "street-address": "24 rue Saint-Lazare", "zip-code": "75009", "shortcut": "", "island": "", "admin-area": "", "subadmin-area": ""}`),
+			&Location{City: "Paris", Country: "France", StreetAddress: "24 rue Saint-Lazare", ZipCode: "75009"},
+			false,
+		},
+		{
+			"should unmarshal original values",
+			[]byte(`{"city": "New York", "city.original": "NYC", "business-name": "Empire State Building", "business-name.original": "the empire state", "island": "Manhattan", "island.original": "manhattan"}`),
+			&Location{City: "New York", CityOriginal: "NYC", BusinessName: "Empire State Building", BusinessNameOriginal: "the empire state", Island: "Manhattan", IslandOriginal: "manhattan"},
+			false,
+		},
+		{
+			"should unmarshal shortcut",
+			[]byte(`{"shortcut": "home", "shortcut.original": "my place"}`),
+			&Location{Shortcut: "home", ShortcutOriginal: "my place"},
+			false,
+		},
+		{"should fail on invalid data", []byte(`12`), &Location{}, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -29,3 +49,66 @@ func TestLocation_UnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestLocation_String(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Location
+		want string
+	}{
+		{"should use simple string", Location{Simple: "Paris", City: "Lyon"}, "Paris"},
+		{"should format address", Location{City: "Paris", Country: "France", StreetAddress: "24 rue Saint-Lazare", ZipCode: "75009"}, "24 rue Saint-Lazare, 75009 Paris, France"},
+		{"should format business", Location{BusinessName: "Empire State Building", City: "New York", AdminArea: "NY"}, "Empire State Building, New York, NY"},
+		{"should format zip code alone", Location{ZipCode: "75009"}, "75009"},
+		{"should format shortcut", Location{Shortcut: "home"}, "home"},
+		{"should format island", Location{Island: "Corsica", Country: "France"}, "Corsica, France"},
+		{"should format empty location", Location{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.in.String())
+		})
+	}
+}
+
+func TestLocations_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []byte
+		want    Locations
+		wantErr bool
+	}{
+		{"should unmarshal list", []byte(`[{"city": "Paris"}, "Lyon"]`), Locations{{City: "Paris"}, {Simple: "Lyon"}}, false},
+		{"should unmarshal single location", []byte(`{"city": "Paris"}`), Locations{{City: "Paris"}}, false},
+		{"should unmarshal single string", []byte(`"Paris"`), Locations{{Simple: "Paris"}}, false},
+		{"should unmarshal empty list", []byte(`[]`), Locations{}, false},
+		{"should fail on invalid data", []byte(`true`), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Locations
+			if err := json.Unmarshal(tt.in, &got); (err != nil) != tt.wantErr {
+				t.Errorf("Locations.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLocation_Params(t *testing.T) {
+	type params struct {
+		Location         Location  `json:"location"`
+		LocationOriginal string    `json:"location.original"`
+		Stops            Locations `json:"stops"`
+	}
+	rw := &Request{QueryResult: QueryResult{Parameters: []byte(`{
+		"location": {"street-address": "24 rue Saint-Lazare", "city": "Paris", "zip-code": "75009", "country": ""},
+		"location.original": "24 rue Saint-Lazare in Paris",
+		"stops": [{"city": "Lyon"}, {"city": "Marseille", "admin-area": "Provence-Alpes-Côte d'Azur"}]
+	}`)}}
+	var p params
+	assert.NoError(t, rw.GetParams(&p))
+	assert.Equal(t, "24 rue Saint-Lazare, 75009 Paris", p.Location.String())
+	assert.Equal(t, "24 rue Saint-Lazare in Paris", p.LocationOriginal)
+	assert.Equal(t, "Lyon; Marseille, Provence-Alpes-Côte d'Azur", p.Stops.String())
+}
@@ -0,0 +1,119 @@
+package dialogflow
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+)
+
+// Middleware wraps a HandlerFunc to add behaviour before and after it runs
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Chain wraps the handler with the given middlewares. The first middleware is
+// the outermost one, which means it runs first when a request comes in and
+// last when the fulfillment is sent back.
+func Chain(h HandlerFunc, m ...Middleware) HandlerFunc {
+	for i := len(m) - 1; i >= 0; i-- {
+		h = m[i](h)
+	}
+	return h
+}
+
+// Before returns a middleware that runs fn before the handler. If fn returns
+// an error, the handler isn't called and the error is returned.
+func Before(fn func(ctx context.Context, req *Request) error) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) (*Fulfillment, error) {
+			if err := fn(ctx, req); err != nil {
+				return nil, err
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// After returns a middleware that runs fn on the fulfillment returned by the
+// handler, allowing to modify or replace it. fn isn't called if the handler
+// returned an error. If the handler returned a nil fulfillment, fn is given
+// an empty one.
+func After(fn func(ctx context.Context, req *Request, dff *Fulfillment) (*Fulfillment, error)) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) (*Fulfillment, error) {
+			dff, err := next(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			if dff == nil {
+				dff = &Fulfillment{}
+			}
+			return fn(ctx, req, dff)
+		}
+	}
+}
+
+// When returns a middleware that diverts the requests matching the predicate
+// to h instead of the wrapped handler
+func When(p Predicate, h HandlerFunc) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) (*Fulfillment, error) {
+			if p(ctx, req) {
+				return h(ctx, req)
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// DefaultContext returns a middleware that adds the given context to the
+// output contexts of the fulfillment, unless the handler already set a
+// context with the same name
+func DefaultContext(name string, lifespan int, params interface{}) Middleware {
+	return After(func(ctx context.Context, req *Request, dff *Fulfillment) (*Fulfillment, error) {
+		c, err := req.NewContext(name, lifespan, params)
+		if err != nil {
+			return nil, err
+		}
+		for _, oc := range dff.OutputContexts {
+			if oc != nil && strings.EqualFold(oc.Name, c.Name) {
+				return dff, nil
+			}
+		}
+		dff.OutputContexts = append(dff.OutputContexts, c)
+		return dff, nil
+	})
+}
+
+// AppendSuggestions returns a middleware that appends suggestion chips with
+// the given titles to the fulfillment messages, for Actions on Google
+func AppendSuggestions(titles ...string) Middleware {
+	return After(func(ctx context.Context, req *Request, dff *Fulfillment) (*Fulfillment, error) {
+		s := Suggestions{}
+		for _, t := range titles {
+			s.Suggestions = append(s.Suggestions, Suggestion{Title: t})
+		}
+		dff.FulfillmentMessages = append(dff.FulfillmentMessages, ForGoogle(s))
+		return dff, nil
+	})
+}
+
+// Logging returns a middleware that logs every handled request with the
+// given logger. The standard logger is used if l is nil.
+func Logging(l *log.Logger) Middleware {
+	logf := log.Printf
+	if l != nil {
+		logf = l.Printf
+	}
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) (*Fulfillment, error) {
+			start := time.Now()
+			dff, err := next(ctx, req)
+			if err != nil {
+				logf("session=%s intent=%q action=%q duration=%s error=%q", req.Session, req.QueryResult.Intent.DisplayName, req.QueryResult.Action, time.Since(start), err)
+			} else {
+				logf("session=%s intent=%q action=%q duration=%s", req.Session, req.QueryResult.Intent.DisplayName, req.QueryResult.Action, time.Since(start))
+			}
+			return dff, err
+		}
+	}
+}
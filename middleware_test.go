@@ -0,0 +1,142 @@
+package dialogflow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recorder(name string, calls *[]string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) (*Fulfillment, error) {
+			*calls = append(*calls, "before "+name)
+			dff, err := next(ctx, req)
+			*calls = append(*calls, "after "+name)
+			return dff, err
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+	h := func(ctx context.Context, req *Request) (*Fulfillment, error) {
+		calls = append(calls, "handler")
+		return nil, nil
+	}
+	_, err := Chain(h, recorder("first", &calls), recorder("second", &calls))(context.Background(), &Request{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"before first", "before second", "handler", "after second", "after first"}, calls)
+}
+
+func TestBefore(t *testing.T) {
+	called := false
+	h := func(ctx context.Context, req *Request) (*Fulfillment, error) {
+		called = true
+		return &Fulfillment{}, nil
+	}
+	m := Before(func(ctx context.Context, req *Request) error {
+		if req.Session == "" {
+			return errors.New("no session")
+		}
+		return nil
+	})
+
+	_, err := m(h)(context.Background(), &Request{})
+	assert.Error(t, err)
+	assert.False(t, called, "handler should not be called")
+
+	_, err = m(h)(context.Background(), &Request{Session: "session"})
+	assert.NoError(t, err)
+	assert.True(t, called, "handler should be called")
+}
+
+func TestAfter(t *testing.T) {
+	m := After(func(ctx context.Context, req *Request, dff *Fulfillment) (*Fulfillment, error) {
+		dff.FulfillmentText += "!"
+		return dff, nil
+	})
+	tests := []struct {
+		name    string
+		handler HandlerFunc
+		want    *Fulfillment
+		wantErr bool
+	}{
+		{"should rewrite", textHandler("hello"), &Fulfillment{FulfillmentText: "hello!"}, false},
+		{"should create fulfillment", func(ctx context.Context, req *Request) (*Fulfillment, error) { return nil, nil }, &Fulfillment{FulfillmentText: "!"}, false},
+		{"should not run on error", func(ctx context.Context, req *Request) (*Fulfillment, error) { return nil, errors.New("broken") }, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m(tt.handler)(context.Background(), &Request{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("After() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDefaultContext(t *testing.T) {
+	req := &Request{Session: "session"}
+	m := DefaultContext("default", 2, map[string]string{"hello": "world"})
+
+	got, err := m(textHandler("hello"))(context.Background(), req)
+	assert.NoError(t, err)
+	if assert.Len(t, got.OutputContexts, 1) {
+		assert.Equal(t, "session/contexts/default", got.OutputContexts[0].Name)
+		assert.Equal(t, 2, got.OutputContexts[0].LifespanCount)
+	}
+
+	h := func(ctx context.Context, req *Request) (*Fulfillment, error) {
+		return &Fulfillment{OutputContexts: Contexts{{"session/contexts/default", 5, nil}}}, nil
+	}
+	got, err = m(h)(context.Background(), req)
+	assert.NoError(t, err)
+	if assert.Len(t, got.OutputContexts, 1, "should not override existing context") {
+		assert.Equal(t, 5, got.OutputContexts[0].LifespanCount)
+	}
+
+	_, err = DefaultContext("wrong", 1, make(chan int))(textHandler("hello"))(context.Background(), req)
+	assert.Error(t, err, "should fail on invalid params")
+}
+
+func TestAppendSuggestions(t *testing.T) {
+	got, err := AppendSuggestions("yes", "no")(textHandler("hello"))(context.Background(), &Request{})
+	assert.NoError(t, err)
+	assert.Equal(t, Messages{ForGoogle(Suggestions{Suggestions: []Suggestion{{Title: "yes"}, {Title: "no"}}})}, got.FulfillmentMessages)
+}
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	l := log.New(&buf, "", 0)
+	req := &Request{Session: "session", QueryResult: QueryResult{Intent: Intent{DisplayName: "greeting"}}}
+
+	_, err := Logging(l)(textHandler("hello"))(context.Background(), req)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(buf.String(), `session=session intent="greeting" action=""`), buf.String())
+	assert.NotContains(t, buf.String(), "error=")
+
+	buf.Reset()
+	_, err = Logging(l)(func(ctx context.Context, req *Request) (*Fulfillment, error) {
+		return nil, errors.New("broken")
+	})(context.Background(), req)
+	assert.Error(t, err)
+	assert.Contains(t, buf.String(), `error="broken"`)
+}
+
+func TestWhen(t *testing.T) {
+	h := When(NegativeSentiment(-0.5, 0), textHandler("human"))(textHandler("bot"))
+
+	got, err := h(context.Background(), sentimentRequest(-0.9, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, "human", got.FulfillmentText)
+
+	got, err = h(context.Background(), &Request{})
+	assert.NoError(t, err)
+	assert.Equal(t, "bot", got.FulfillmentText)
+}
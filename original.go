@@ -0,0 +1,62 @@
+package dialogflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrNoPayloadDecoder is returned when no decoder is registered for the
+// source of the original request
+var ErrNoPayloadDecoder = errors.New("dialogflow: no payload decoder registered")
+
+// OriginalDetectIntentRequest is the request the integration (Actions on
+// Google, Facebook, Slack…) sent to dialogflow
+type OriginalDetectIntentRequest struct {
+	Source  string          `json:"source,omitempty"`
+	Version string          `json:"version,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Platform returns the platform associated to the source of the request, or
+// Unspecified if the source is unknown
+func (o OriginalDetectIntentRequest) Platform() Platform {
+	return PlatformFromSource(o.Source)
+}
+
+// GetPayload simply unmarshals the payload to the given struct and returns an
+// error if it's not possible
+func (o OriginalDetectIntentRequest) GetPayload(i interface{}) error {
+	return json.Unmarshal(o.Payload, &i)
+}
+
+// DecodePayload decodes the payload using the decoder registered for the
+// source of the request, which is case insensitive. An error wrapping
+// ErrNoPayloadDecoder is returned if there is none.
+func (o OriginalDetectIntentRequest) DecodePayload() (interface{}, error) {
+	payloadDecoders.RLock()
+	d, ok := payloadDecoders.decoders[strings.ToLower(o.Source)]
+	payloadDecoders.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w for source %q", ErrNoPayloadDecoder, o.Source)
+	}
+	return d(o.Payload)
+}
+
+// PayloadDecoder decodes the payload of an original request
+type PayloadDecoder func(payload json.RawMessage) (interface{}, error)
+
+var payloadDecoders = struct {
+	sync.RWMutex
+	decoders map[string]PayloadDecoder
+}{decoders: make(map[string]PayloadDecoder)}
+
+// RegisterPayloadDecoder registers the decoder used by DecodePayload for the
+// given source, replacing any existing one. Sources are case insensitive.
+func RegisterPayloadDecoder(source string, d PayloadDecoder) {
+	payloadDecoders.Lock()
+	defer payloadDecoders.Unlock()
+	payloadDecoders.decoders[strings.ToLower(source)] = d
+}
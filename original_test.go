@@ -0,0 +1,75 @@
+package dialogflow
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlatformFromSource(t *testing.T) {
+	tests := []struct {
+		source string
+		want   Platform
+	}{
+		{"google", ActionsOnGoogle},
+		{"facebook", Facebook},
+		{"slack", Slack},
+		{"slack_testbot", Slack},
+		{"telegram", Telegram},
+		{"Telegram", Telegram},
+		{"kik", Kik},
+		{"skype", Skype},
+		{"line", Line},
+		{"viber", Viber},
+		{"twilio", Unspecified},
+		{"", Unspecified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			assert.Equal(t, tt.want, PlatformFromSource(tt.source))
+			assert.Equal(t, tt.want, OriginalDetectIntentRequest{Source: tt.source}.Platform())
+		})
+	}
+}
+
+func TestOriginalDetectIntentRequest_GetPayload(t *testing.T) {
+	type payload struct {
+		Data string `json:"data"`
+	}
+	o := OriginalDetectIntentRequest{Source: "slack", Payload: []byte(`{"data": "hello"}`)}
+	var p payload
+	assert.NoError(t, o.GetPayload(&p))
+	assert.Equal(t, payload{"hello"}, p)
+
+	o.Payload = nil
+	assert.Error(t, o.GetPayload(&p))
+}
+
+func TestOriginalDetectIntentRequest_DecodePayload(t *testing.T) {
+	type payload struct {
+		Data string `json:"data"`
+	}
+	RegisterPayloadDecoder("test", func(raw json.RawMessage) (interface{}, error) {
+		var p payload
+		err := json.Unmarshal(raw, &p)
+		return p, err
+	})
+	defer func() {
+		payloadDecoders.Lock()
+		delete(payloadDecoders.decoders, "test")
+		payloadDecoders.Unlock()
+	}()
+
+	got, err := OriginalDetectIntentRequest{Source: "test", Payload: []byte(`{"data": "hello"}`)}.DecodePayload()
+	assert.NoError(t, err)
+	assert.Equal(t, payload{"hello"}, got)
+
+	got, err = OriginalDetectIntentRequest{Source: "Test", Payload: []byte(`{"data": "hello"}`)}.DecodePayload()
+	assert.NoError(t, err, "sources should be case insensitive")
+	assert.Equal(t, payload{"hello"}, got)
+
+	_, err = OriginalDetectIntentRequest{Source: "unknown"}.DecodePayload()
+	assert.True(t, errors.Is(err, ErrNoPayloadDecoder))
+}
@@ -0,0 +1,156 @@
+package dialogflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ErrParamNotFound is returned when a parameter isn't present
+var ErrParamNotFound = errors.New("dialogflow: parameter not found")
+
+// Params is a read-only view over the parameters of a request, allowing to
+// retrieve them one by one without declaring a struct. Getters return the
+// zero value when the parameter is missing or can't be converted.
+type Params struct {
+	values map[string]json.RawMessage
+}
+
+// Params returns the parameters of the request. The parameters of the query
+// take precedence over the parameters of the output contexts, which are
+// taken in order.
+func (rw *Request) Params() Params {
+	p := Params{values: make(map[string]json.RawMessage)}
+	var sources []json.RawMessage
+	for i := len(rw.QueryResult.OutputContexts) - 1; i >= 0; i-- {
+		if c := rw.QueryResult.OutputContexts[i]; c != nil {
+			sources = append(sources, c.Parameters)
+		}
+	}
+	sources = append(sources, rw.QueryResult.Parameters)
+	for _, s := range sources {
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(s, &m); err != nil {
+			continue
+		}
+		for k, v := range m {
+			p.values[k] = v
+		}
+	}
+	return p
+}
+
+// Names returns the sorted names of all the parameters
+func (p Params) Names() []string {
+	names := make([]string, 0, len(p.values))
+	for k := range p.values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Has returns true if the parameter is present, even if empty
+func (p Params) Has(name string) bool {
+	_, ok := p.values[name]
+	return ok
+}
+
+// IsSet returns true if the parameter is present and not empty. Dialogflow
+// sends empty strings or lists for parameters that weren't filled.
+func (p Params) IsSet(name string) bool {
+	v, ok := p.values[name]
+	if !ok {
+		return false
+	}
+	switch string(bytes.TrimSpace(v)) {
+	case `""`, `[]`, `{}`, `null`:
+		return false
+	}
+	return true
+}
+
+// Raw returns the raw JSON value of the parameter, or nil if it's missing
+func (p Params) Raw(name string) json.RawMessage {
+	return p.values[name]
+}
+
+// Decode unmarshals the parameter to the given value, which can for example
+// be one of the system entity types such as Location or Duration
+func (p Params) Decode(name string, i interface{}) error {
+	v, ok := p.values[name]
+	if !ok {
+		return ErrParamNotFound
+	}
+	return json.Unmarshal(v, i)
+}
+
+// Original returns the original text the user typed or said for the
+// parameter, which dialogflow sends as "<name>.original"
+func (p Params) Original(name string) string {
+	return p.String(name + ".original")
+}
+
+// String returns the parameter as a string. Numbers are formatted.
+func (p Params) String(name string) string {
+	s, _ := unmarshalString(p.values[name])
+	return s
+}
+
+// Float returns the parameter as a float. Strings containing numbers are
+// parsed.
+func (p Params) Float(name string) float64 {
+	f, _ := strconv.ParseFloat(p.String(name), 64)
+	return f
+}
+
+// Int returns the parameter as an integer, truncating decimals
+func (p Params) Int(name string) int {
+	return int(p.Float(name))
+}
+
+// Bool returns the parameter as a boolean. Strings such as "true" or "1" are
+// parsed.
+func (p Params) Bool(name string) bool {
+	var b bool
+	if err := json.Unmarshal(p.values[name], &b); err == nil {
+		return b
+	}
+	b, _ = strconv.ParseBool(p.String(name))
+	return b
+}
+
+// Strings returns the parameter as a list of strings. A single value gives a
+// list with one element.
+func (p Params) Strings(name string) []string {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(p.values[name], &raws); err != nil {
+		if s, ok := unmarshalString(p.values[name]); ok && s != "" {
+			return []string{s}
+		}
+		return nil
+	}
+	var out []string
+	for _, r := range raws {
+		if s, ok := unmarshalString(r); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Time returns the parameter as a time, using the DateTime entity. If the
+// parameter is a period, its start is returned.
+func (p Params) Time(name string) time.Time {
+	var d DateTime
+	if err := json.Unmarshal(p.values[name], &d); err != nil {
+		return time.Time{}
+	}
+	if d.IsPeriod() {
+		return d.Start
+	}
+	return d.Time
+}
@@ -0,0 +1,89 @@
+package dialogflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func paramsRequest() *Request {
+	return &Request{QueryResult: QueryResult{
+		Parameters: []byte(`{
+			"name": "John",
+			"name.original": "john",
+			"age": 30,
+			"price": "12.5",
+			"vip": true,
+			"newsletter": "false",
+			"toppings": ["cheese", "ham"],
+			"size": "",
+			"date": "2018-10-01T12:00:00+02:00",
+			"period": {"startDate": "2018-10-01T00:00:00+02:00", "endDate": "2018-10-07T23:59:59+02:00"},
+			"duration": {"amount": 10, "unit": "min"}
+		}`),
+		OutputContexts: Contexts{
+			{"projects/p/agent/sessions/s/contexts/order", 2, []byte(`{"name": "Jane", "order": "1234", "city": "Paris"}`)},
+			{"projects/p/agent/sessions/s/contexts/old", 1, []byte(`{"city": "Lyon", "country": "France"}`)},
+			{"projects/p/agent/sessions/s/contexts/empty", 1, nil},
+		},
+	}}
+}
+
+func TestRequest_Params(t *testing.T) {
+	p := paramsRequest().Params()
+	assert.Equal(t, "John", p.String("name"), "query parameters should take precedence")
+	assert.Equal(t, "1234", p.String("order"), "should include context parameters")
+	assert.Equal(t, "Paris", p.String("city"), "first contexts should take precedence")
+	assert.Equal(t, "France", p.String("country"))
+	assert.Len(t, p.Names(), 14)
+	assert.Equal(t, "age", p.Names()[0])
+
+	assert.Empty(t, (&Request{}).Params().Names())
+}
+
+func TestParams_Getters(t *testing.T) {
+	p := paramsRequest().Params()
+
+	assert.True(t, p.Has("size"))
+	assert.False(t, p.IsSet("size"))
+	assert.True(t, p.IsSet("name"))
+	assert.False(t, p.Has("missing"))
+	assert.False(t, p.IsSet("missing"))
+
+	assert.Equal(t, "john", p.Original("name"))
+	assert.Equal(t, "", p.Original("age"))
+	assert.Equal(t, "30", p.String("age"))
+	assert.Equal(t, "", p.String("toppings"))
+
+	assert.Equal(t, 30, p.Int("age"))
+	assert.Equal(t, 12, p.Int("price"))
+	assert.Equal(t, 0, p.Int("name"))
+	assert.Equal(t, 12.5, p.Float("price"))
+	assert.Equal(t, float64(0), p.Float("missing"))
+
+	assert.True(t, p.Bool("vip"))
+	assert.False(t, p.Bool("newsletter"))
+	assert.False(t, p.Bool("missing"))
+
+	assert.Equal(t, []string{"cheese", "ham"}, p.Strings("toppings"))
+	assert.Equal(t, []string{"John"}, p.Strings("name"))
+	assert.Nil(t, p.Strings("size"))
+	assert.Nil(t, p.Strings("missing"))
+
+	assert.Equal(t, mustTime("2018-10-01T12:00:00+02:00"), p.Time("date"))
+	assert.Equal(t, mustTime("2018-10-01T00:00:00+02:00"), p.Time("period"))
+	assert.Equal(t, time.Time{}, p.Time("name"))
+
+	assert.Equal(t, `{"amount": 10, "unit": "min"}`, string(p.Raw("duration")))
+	assert.Nil(t, p.Raw("missing"))
+}
+
+func TestParams_Decode(t *testing.T) {
+	p := paramsRequest().Params()
+	var d Duration
+	assert.NoError(t, p.Decode("duration", &d))
+	assert.Equal(t, Duration{Amount{10, "min"}}, d)
+	assert.Equal(t, ErrParamNotFound, p.Decode("missing", &d))
+	assert.Error(t, p.Decode("name", &d))
+}
@@ -1,5 +1,7 @@
 package dialogflow
 
+import "strings"
+
 // Platform is a simple type intended to be used with responses
 type Platform string
 
@@ -15,3 +17,26 @@ const (
 	Viber           Platform = "VIBER"
 	ActionsOnGoogle Platform = "ACTIONS_ON_GOOGLE"
 )
+
+// sources maps the sources of the original detect intent requests to the
+// platforms
+var sources = map[string]Platform{
+	"google":        ActionsOnGoogle,
+	"facebook":      Facebook,
+	"slack":         Slack,
+	"slack_testbot": Slack,
+	"telegram":      Telegram,
+	"kik":           Kik,
+	"skype":         Skype,
+	"line":          Line,
+	"viber":         Viber,
+}
+
+// PlatformFromSource returns the platform associated to the source of an
+// original detect intent request, or Unspecified if the source is unknown
+func PlatformFromSource(source string) Platform {
+	if p, ok := sources[strings.ToLower(source)]; ok {
+		return p
+	}
+	return Unspecified
+}
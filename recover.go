@@ -0,0 +1,94 @@
+package dialogflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"strings"
+)
+
+// Localized associates language codes (such as "en", "fr" or "fr-CA") to
+// fulfillments. The empty language code can be used as a default.
+type Localized map[string]*Fulfillment
+
+// For returns the fulfillment matching the language code. The full language
+// code is tried first, then the base language ("fr" for "fr-CA") and finally
+// the default. Language codes are case insensitive. Nil is returned if
+// nothing matches.
+func (l Localized) For(lang string) *Fulfillment {
+	lang = strings.ToLower(lang)
+	candidates := []string{lang}
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		candidates = append(candidates, lang[:i])
+	}
+	candidates = append(candidates, "")
+	for _, c := range candidates {
+		for k, f := range l {
+			if strings.ToLower(k) == c {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// Fallback returns a FallbackFunc answering with the fulfillment matching the
+// language code of the request
+func (l Localized) Fallback() FallbackFunc {
+	return func(ctx context.Context, req *Request, err error) *Fulfillment {
+		return l.localize(req)
+	}
+}
+
+// localize returns a copy of the fulfillment matching the language code of
+// the request, or an empty fulfillment if there is none
+func (l Localized) localize(req *Request) *Fulfillment {
+	f := l.For(req.QueryResult.LanguageCode)
+	if f == nil {
+		return &Fulfillment{}
+	}
+	return copyFulfillment(f)
+}
+
+// PanicError is returned by middlewares running the handler in another
+// goroutine, such as Deadline, when the handler panics
+type PanicError struct {
+	Value interface{} // The value given to panic
+	Stack []byte      // The stack trace of the goroutine that panicked
+}
+
+// Error implements the error interface
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("dialogflow: panic in handler: %v", e.Value)
+}
+
+// Recover returns a middleware that recovers from panics occurring in the
+// handler. The panic and the stack trace are logged along with the session
+// of the request, using the given logger or the standard one if nil. The
+// fulfillment matching the language code of the request is then sent back.
+// A *PanicError returned by the handler is handled the same way.
+func Recover(l *log.Logger, fallback Localized) Middleware {
+	logf := log.Printf
+	if l != nil {
+		logf = l.Printf
+	}
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) (dff *Fulfillment, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					logf("panic in session %s: %v\n%s", req.Session, r, debug.Stack())
+					dff, err = fallback.localize(req), nil
+				}
+			}()
+			dff, err = next(ctx, req)
+			var perr *PanicError
+			if errors.As(err, &perr) {
+				logf("panic in session %s: %v\n%s", req.Session, perr.Value, perr.Stack)
+				return fallback.localize(req), nil
+			}
+			return dff, err
+		}
+	}
+}
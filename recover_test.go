@@ -0,0 +1,68 @@
+package dialogflow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalized_For(t *testing.T) {
+	en := &Fulfillment{FulfillmentText: "Sorry"}
+	fr := &Fulfillment{FulfillmentText: "Désolé"}
+	frCA := &Fulfillment{FulfillmentText: "Désolé, mon chum"}
+	l := Localized{"": en, "fr": fr, "fr-CA": frCA}
+
+	tests := []struct {
+		name string
+		l    Localized
+		lang string
+		want *Fulfillment
+	}{
+		{"should match exactly", l, "fr-CA", frCA},
+		{"should match case insensitive", l, "fr-ca", frCA},
+		{"should match base language", l, "fr-FR", fr},
+		{"should match base language without region", l, "fr", fr},
+		{"should use default", l, "de", en},
+		{"should use default without language", l, "", en},
+		{"should return nil", Localized{"fr": fr}, "de", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.l.For(tt.lang))
+		})
+	}
+}
+
+func TestLocalized_Fallback(t *testing.T) {
+	l := Localized{"fr": &Fulfillment{FulfillmentText: "Désolé"}}
+	fb := l.Fallback()
+	got := fb(context.Background(), &Request{QueryResult: QueryResult{LanguageCode: "fr"}}, errors.New("broken"))
+	assert.Equal(t, "Désolé", got.FulfillmentText)
+	got = fb(context.Background(), &Request{QueryResult: QueryResult{LanguageCode: "en"}}, errors.New("broken"))
+	assert.Equal(t, &Fulfillment{}, got)
+}
+
+func TestRecover(t *testing.T) {
+	var buf bytes.Buffer
+	l := log.New(&buf, "", 0)
+	fallback := Localized{"en": &Fulfillment{FulfillmentText: "Sorry"}}
+	req := &Request{Session: "projects/p/agent/sessions/1234", QueryResult: QueryResult{LanguageCode: "en-US"}}
+
+	got, err := Recover(l, fallback)(textHandler("hello"))(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", got.FulfillmentText)
+	assert.Empty(t, buf.String())
+
+	got, err = Recover(l, fallback)(func(ctx context.Context, req *Request) (*Fulfillment, error) {
+		var f *Fulfillment
+		return &Fulfillment{FulfillmentText: f.FulfillmentText}, nil
+	})(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "Sorry", got.FulfillmentText)
+	assert.Contains(t, buf.String(), "panic in session projects/p/agent/sessions/1234: runtime error")
+	assert.Contains(t, buf.String(), "goroutine")
+}
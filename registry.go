@@ -0,0 +1,77 @@
+package dialogflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var richMessages = struct {
+	sync.RWMutex
+	types map[string]reflect.Type
+}{types: make(map[string]reflect.Type)}
+
+func init() {
+	for _, m := range []RichMessage{
+		Text{},
+		Image{},
+		QuickReplies{},
+		Card{},
+		PayloadWrapper{},
+		SimpleResponsesWrapper{},
+		BasicCard{},
+		Suggestions{},
+		LinkOutSuggestion{},
+		ListSelect{},
+		CarouselSelect{},
+	} {
+		RegisterRichMessage(m)
+	}
+}
+
+// RegisterRichMessage registers the type of the given rich message under the
+// key returned by its GetKey method. When unmarshalling a Message, the value
+// associated to this key will be decoded to a new value of this type.
+// Registering a type for an existing key replaces it, which allows to
+// support custom or newer message kinds.
+func RegisterRichMessage(m RichMessage) {
+	richMessages.Lock()
+	defer richMessages.Unlock()
+	richMessages.types[m.GetKey()] = reflect.TypeOf(m)
+}
+
+// newRichMessage decodes the raw JSON to the rich message type registered
+// for the key. An UnknownMessage is returned if no type is registered.
+func newRichMessage(key string, raw json.RawMessage) (RichMessage, error) {
+	richMessages.RLock()
+	t, ok := richMessages.types[key]
+	richMessages.RUnlock()
+	if !ok {
+		return UnknownMessage{Key: key, Raw: raw}, nil
+	}
+
+	v := reflect.New(t)
+	if err := json.Unmarshal(raw, v.Interface()); err != nil {
+		return nil, fmt.Errorf("dialogflow: unable to decode %q message: %v", key, err)
+	}
+	return v.Elem().Interface().(RichMessage), nil
+}
+
+// UnknownMessage holds a rich message whose key isn't registered. Its raw
+// JSON is kept as is so that it can be marshalled back.
+type UnknownMessage struct {
+	Key string
+	Raw json.RawMessage
+}
+
+// GetKey implements the RichMessage interface and returns the JSON key
+// associated with the message
+func (u UnknownMessage) GetKey() string {
+	return u.Key
+}
+
+// MarshalJSON implements the Marshaller interface and returns the raw JSON
+func (u UnknownMessage) MarshalJSON() ([]byte, error) {
+	return u.Raw, nil
+}
@@ -0,0 +1,40 @@
+package dialogflow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type custom struct {
+	Value string `json:"value"`
+}
+
+func (custom) GetKey() string {
+	return "custom"
+}
+
+func TestRegisterRichMessage(t *testing.T) {
+	var m Message
+	assert.NoError(t, json.Unmarshal([]byte(`{"custom": {"value": "hello"}}`), &m))
+	assert.IsType(t, UnknownMessage{}, m.RichMessage, "should not be registered yet")
+
+	RegisterRichMessage(custom{})
+	defer func() {
+		richMessages.Lock()
+		delete(richMessages.types, "custom")
+		richMessages.Unlock()
+	}()
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"custom": {"value": "hello"}}`), &m))
+	assert.Equal(t, custom{Value: "hello"}, m.RichMessage)
+}
+
+func TestUnknownMessage(t *testing.T) {
+	u := UnknownMessage{Key: "tableCard", Raw: json.RawMessage(`{"title":"table"}`)}
+	assert.Equal(t, "tableCard", u.GetKey())
+	b, err := u.MarshalJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"title":"table"}`, string(b))
+}
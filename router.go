@@ -0,0 +1,127 @@
+package dialogflow
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// ErrNoHandler is returned by the router when no handler is registered for
+// the incoming intent or action and no default handler was set
+var ErrNoHandler = errors.New("dialogflow: no handler registered")
+
+// Predicate reports whether a request matches a condition
+type Predicate func(ctx context.Context, req *Request) bool
+
+// Router dispatches incoming dialogflow requests to the registered handlers.
+// Handlers are matched in the following order : predicates, intent display
+// name, intent name, action, and finally the default handler.
+// Router implements the http.Handler interface.
+type Router struct {
+	// Fallback builds the fulfillment sent back when a handler returns an
+	// error. DefaultFallback is used if nil.
+	Fallback FallbackFunc
+	// DecodeOptions are the options used to decode incoming requests
+	DecodeOptions DecodeOptions
+	// ErrorLog is an optional logger for requests that couldn't be decoded
+	ErrorLog *log.Logger
+
+	intents     map[string]HandlerFunc
+	actions     map[string]HandlerFunc
+	predicates  []predicateHandler
+	def         HandlerFunc
+	middlewares []Middleware
+}
+
+// predicateHandler is a handler registered along with a predicate
+type predicateHandler struct {
+	p Predicate
+	h HandlerFunc
+}
+
+// NewRouter returns a new empty router
+func NewRouter() *Router {
+	return &Router{
+		intents: make(map[string]HandlerFunc),
+		actions: make(map[string]HandlerFunc),
+	}
+}
+
+// HandleIntent registers a handler for the given intent. The name can either
+// be the display name of the intent, or its full name
+// (projects/<project>/agent/intents/<id>)
+func (r *Router) HandleIntent(name string, h HandlerFunc) {
+	r.intents[name] = h
+}
+
+// HandleAction registers a handler for the given action
+func (r *Router) HandleAction(action string, h HandlerFunc) {
+	r.actions[action] = h
+}
+
+// HandleWhen registers a handler for the requests matching the predicate,
+// such as NegativeSentiment. Predicates are checked in the order they were
+// registered, before the intents and actions.
+func (r *Router) HandleWhen(p Predicate, h HandlerFunc) {
+	r.predicates = append(r.predicates, predicateHandler{p, h})
+}
+
+// Default registers the handler used when no other handler matches the
+// incoming request
+func (r *Router) Default(h HandlerFunc) {
+	r.def = h
+}
+
+// Use appends middlewares to the router. They wrap every handler of the
+// router, in the order they were added.
+func (r *Router) Use(m ...Middleware) {
+	r.middlewares = append(r.middlewares, m...)
+}
+
+// Handle dispatches the request to the matching handler and returns the
+// resulting fulfillment. ErrNoHandler is returned if no handler matches.
+// Errors returned by the handler are turned into a fulfillment using the
+// router's Fallback.
+// Handle has the HandlerFunc signature, which means a Router can be used as
+// a handler.
+func (r *Router) Handle(ctx context.Context, req *Request) (*Fulfillment, error) {
+	ctx = WithRequest(ctx, req)
+	h := r.match(ctx, req)
+	if h == nil {
+		return nil, ErrNoHandler
+	}
+	fb := r.Fallback
+	if fb == nil {
+		fb = DefaultFallback
+	}
+	return withFallback(Chain(h, r.middlewares...), fb)(ctx, req)
+}
+
+// match returns the handler associated to the request, or nil if there is
+// none
+func (r *Router) match(ctx context.Context, req *Request) HandlerFunc {
+	for _, ph := range r.predicates {
+		if ph.p(ctx, req) {
+			return ph.h
+		}
+	}
+	qr := req.QueryResult
+	if h, ok := r.intents[qr.Intent.DisplayName]; ok && qr.Intent.DisplayName != "" {
+		return h
+	}
+	if h, ok := r.intents[qr.Intent.Name]; ok && qr.Intent.Name != "" {
+		return h
+	}
+	if h, ok := r.actions[qr.Action]; ok && qr.Action != "" {
+		return h
+	}
+	return r.def
+}
+
+// ServeHTTP implements the http.Handler interface. The request body is
+// decoded to a Request using DecodeRequest, dispatched, and the returned
+// fulfillment is encoded in the response.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	serve(w, req, r.DecodeOptions, r.ErrorLog, r.Handle)
+}
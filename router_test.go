@@ -0,0 +1,143 @@
+package dialogflow
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func textHandler(text string) HandlerFunc {
+	return func(ctx context.Context, req *Request) (*Fulfillment, error) {
+		return &Fulfillment{FulfillmentText: text}, nil
+	}
+}
+
+func TestRouter_Handle(t *testing.T) {
+	r := NewRouter()
+	r.HandleIntent("greeting", textHandler("display name"))
+	r.HandleIntent("projects/p/agent/intents/1234", textHandler("name"))
+	r.HandleAction("order.create", textHandler("action"))
+
+	tests := []struct {
+		name    string
+		qr      QueryResult
+		def     HandlerFunc
+		want    string
+		wantErr error
+	}{
+		{"should match display name", QueryResult{Intent: Intent{DisplayName: "greeting", Name: "projects/p/agent/intents/1234"}}, nil, "display name", nil},
+		{"should match intent name", QueryResult{Intent: Intent{DisplayName: "other", Name: "projects/p/agent/intents/1234"}}, nil, "name", nil},
+		{"should match action", QueryResult{Action: "order.create", Intent: Intent{DisplayName: "other"}}, nil, "action", nil},
+		{"should use default", QueryResult{Action: "unknown"}, textHandler("default"), "default", nil},
+		{"should fail without default", QueryResult{Action: "unknown"}, nil, "", ErrNoHandler},
+		{"should not match empty values", QueryResult{}, nil, "", ErrNoHandler},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.Default(tt.def)
+			got, err := r.Handle(context.Background(), &Request{QueryResult: tt.qr})
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr != nil {
+				return
+			}
+			assert.Equal(t, tt.want, got.FulfillmentText)
+		})
+	}
+}
+
+func TestRouter_ServeHTTP(t *testing.T) {
+	r := NewRouter()
+	r.HandleIntent("greeting", textHandler("hello"))
+	r.HandleIntent("broken", func(ctx context.Context, req *Request) (*Fulfillment, error) {
+		return nil, errors.New("broken")
+	})
+	r.HandleIntent("empty", func(ctx context.Context, req *Request) (*Fulfillment, error) {
+		return nil, nil
+	})
+	r.Fallback = FallbackFulfillment(&Fulfillment{FulfillmentText: "sorry"})
+	r.DecodeOptions.MaxBodySize = 100
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		status int
+		want   string
+	}{
+		{"should respond", http.MethodPost, `{"session": "session", "queryResult": {"intent": {"displayName": "greeting"}}}`, http.StatusOK, `{"fulfillmentText": "hello", "followupEventInput": {"name": ""}}`},
+		{"should respond with empty fulfillment", http.MethodPost, `{"session": "session", "queryResult": {"intent": {"displayName": "empty"}}}`, http.StatusOK, `{"followupEventInput": {"name": ""}}`},
+		{"should refuse other methods", http.MethodGet, ``, http.StatusMethodNotAllowed, ``},
+		{"should fail on invalid body", http.MethodPost, `{"queryResult":`, http.StatusBadRequest, ``},
+		{"should fail on null body", http.MethodPost, `null`, http.StatusBadRequest, ``},
+		{"should fail on missing session", http.MethodPost, `{"queryResult": {"intent": {"displayName": "greeting"}}}`, http.StatusBadRequest, ``},
+		{"should fail on too large body", http.MethodPost, `{"session": "session", "queryResult": {"queryText": "` + strings.Repeat("a", 100) + `"}}`, http.StatusRequestEntityTooLarge, ``},
+		{"should not find handler", http.MethodPost, `{"session": "session", "queryResult": {"intent": {"displayName": "unknown"}}}`, http.StatusNotFound, ``},
+		{"should use fallback on handler error", http.MethodPost, `{"session": "session", "queryResult": {"intent": {"displayName": "broken"}}}`, http.StatusOK, `{"fulfillmentText": "sorry", "followupEventInput": {"name": ""}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, "/webhook", strings.NewReader(tt.body)))
+			assert.Equal(t, tt.status, w.Code)
+			if tt.want == "" {
+				return
+			}
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			if err := JSONStringsEqual(w.Body.String(), tt.want); err != nil {
+				t.Errorf("Router.ServeHTTP() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRouter_Handle_Fallback(t *testing.T) {
+	r := NewRouter()
+	r.HandleIntent("broken", func(ctx context.Context, req *Request) (*Fulfillment, error) {
+		return nil, errors.New("broken")
+	})
+
+	got, err := r.Handle(context.Background(), &Request{QueryResult: QueryResult{Intent: Intent{DisplayName: "broken"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, &Fulfillment{}, got, "should use the default fallback")
+
+	r.Fallback = func(ctx context.Context, req *Request, err error) *Fulfillment {
+		return &Fulfillment{FulfillmentText: err.Error()}
+	}
+	got, err = r.Handle(context.Background(), &Request{QueryResult: QueryResult{Intent: Intent{DisplayName: "broken"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "broken", got.FulfillmentText, "should use the configured fallback")
+}
+
+func TestRouter_Use(t *testing.T) {
+	var calls []string
+	r := NewRouter()
+	r.Use(recorder("first", &calls), recorder("second", &calls))
+	r.HandleIntent("greeting", textHandler("hello"))
+
+	_, err := r.Handle(context.Background(), &Request{QueryResult: QueryResult{Intent: Intent{DisplayName: "greeting"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"before first", "before second", "after second", "after first"}, calls)
+}
+
+func TestRouter_HandleWhen(t *testing.T) {
+	r := NewRouter()
+	r.HandleIntent("order", textHandler("order"))
+	r.HandleWhen(NegativeSentiment(-0.5, 0), textHandler("human"))
+
+	req := sentimentRequest(-0.9, 1)
+	req.QueryResult.Intent.DisplayName = "order"
+	got, err := r.Handle(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "human", got.FulfillmentText, "predicates should take precedence")
+
+	req = sentimentRequest(0.9, 1)
+	req.QueryResult.Intent.DisplayName = "order"
+	got, err = r.Handle(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "order", got.FulfillmentText)
+}
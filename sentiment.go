@@ -0,0 +1,36 @@
+package dialogflow
+
+import "context"
+
+// SentimentAnalysisResult holds the result of the sentiment analysis of the
+// query
+type SentimentAnalysisResult struct {
+	QueryTextSentiment Sentiment `json:"queryTextSentiment,omitempty"`
+}
+
+// Sentiment is the sentiment of a text
+type Sentiment struct {
+	Score     float64 `json:"score,omitempty"`     // From -1.0 (negative) to 1.0 (positive)
+	Magnitude float64 `json:"magnitude,omitempty"` // Strength of the emotion, from 0 to +inf
+}
+
+// Sentiment returns the sentiment of the query. The boolean is false if
+// sentiment analysis isn't enabled on the agent.
+func (rw *Request) Sentiment() (Sentiment, bool) {
+	if rw.QueryResult.SentimentAnalysisResult == nil {
+		return Sentiment{}, false
+	}
+	return rw.QueryResult.SentimentAnalysisResult.QueryTextSentiment, true
+}
+
+// NegativeSentiment returns a predicate matching the requests whose query
+// sentiment score is lower than or equal to score, with a magnitude greater
+// than or equal to magnitude. Requests without sentiment analysis never
+// match. For example NegativeSentiment(-0.5, 0.5) can be used to escalate
+// angry users to a human agent.
+func NegativeSentiment(score, magnitude float64) Predicate {
+	return func(ctx context.Context, req *Request) bool {
+		s, ok := req.Sentiment()
+		return ok && s.Score <= score && s.Magnitude >= magnitude
+	}
+}
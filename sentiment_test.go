@@ -0,0 +1,48 @@
+package dialogflow
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sentimentRequest(score, magnitude float64) *Request {
+	return &Request{QueryResult: QueryResult{
+		SentimentAnalysisResult: &SentimentAnalysisResult{QueryTextSentiment: Sentiment{Score: score, Magnitude: magnitude}},
+	}}
+}
+
+func TestRequest_Sentiment(t *testing.T) {
+	var rw Request
+	in := `{"queryResult": {"sentimentAnalysisResult": {"queryTextSentiment": {"score": -0.8, "magnitude": 1.6}}}}`
+	assert.NoError(t, json.Unmarshal([]byte(in), &rw))
+	s, ok := rw.Sentiment()
+	assert.True(t, ok)
+	assert.Equal(t, Sentiment{Score: -0.8, Magnitude: 1.6}, s)
+
+	_, ok = (&Request{}).Sentiment()
+	assert.False(t, ok)
+}
+
+func TestNegativeSentiment(t *testing.T) {
+	p := NegativeSentiment(-0.5, 0.5)
+	tests := []struct {
+		name string
+		req  *Request
+		want bool
+	}{
+		{"should match strongly negative", sentimentRequest(-0.8, 1.6), true},
+		{"should match on bounds", sentimentRequest(-0.5, 0.5), true},
+		{"should not match mildly negative", sentimentRequest(-0.2, 1.6), false},
+		{"should not match weak magnitude", sentimentRequest(-0.8, 0.1), false},
+		{"should not match positive", sentimentRequest(0.9, 2), false},
+		{"should not match without analysis", &Request{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, p(context.Background(), tt.req))
+		})
+	}
+}
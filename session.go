@@ -0,0 +1,69 @@
+package dialogflow
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMalformedSession is returned when a session path can't be parsed
+var ErrMalformedSession = errors.New("dialogflow: malformed session path")
+
+// SessionPath is the parsed form of a session, which can either be
+// projects/<project>/agent/sessions/<session> or
+// projects/<project>/agent/environments/<env>/users/<user>/sessions/<session>
+type SessionPath struct {
+	ProjectID   string
+	Environment string // Empty for the draft agent
+	UserID      string // Only present along with an environment
+	SessionID   string
+}
+
+// ParseSessionPath parses a session path. An error wrapping
+// ErrMalformedSession is returned if it doesn't match one of the two known
+// forms.
+func ParseSessionPath(s string) (SessionPath, error) {
+	var p SessionPath
+
+	parts := strings.Split(s, "/")
+	for _, part := range parts {
+		if part == "" {
+			return p, fmt.Errorf("%w %q", ErrMalformedSession, s)
+		}
+	}
+	switch {
+	case len(parts) == 5 && parts[0] == "projects" && parts[2] == "agent" && parts[3] == "sessions":
+		p.ProjectID, p.SessionID = parts[1], parts[4]
+	case len(parts) == 9 && parts[0] == "projects" && parts[2] == "agent" && parts[3] == "environments" &&
+		parts[5] == "users" && parts[7] == "sessions":
+		p.ProjectID, p.Environment, p.UserID, p.SessionID = parts[1], parts[4], parts[6], parts[8]
+	default:
+		return p, fmt.Errorf("%w %q", ErrMalformedSession, s)
+	}
+	return p, nil
+}
+
+// String returns the full session path
+func (p SessionPath) String() string {
+	if p.Environment == "" {
+		return fmt.Sprintf("projects/%s/agent/sessions/%s", p.ProjectID, p.SessionID)
+	}
+	return fmt.Sprintf("projects/%s/agent/environments/%s/users/%s/sessions/%s", p.ProjectID, p.Environment, p.UserID, p.SessionID)
+}
+
+// Context returns the full name of the context with the given short name in
+// this session
+func (p SessionPath) Context(name string) string {
+	return p.String() + "/contexts/" + name
+}
+
+// EntityType returns the full name of the session entity type with the given
+// display name in this session
+func (p SessionPath) EntityType(name string) string {
+	return p.String() + "/entityTypes/" + name
+}
+
+// SessionPath parses the session of the request
+func (rw *Request) SessionPath() (SessionPath, error) {
+	return ParseSessionPath(rw.Session)
+}
@@ -0,0 +1,66 @@
+package dialogflow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSessionPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    SessionPath
+		wantErr bool
+	}{
+		{
+			"should parse draft session",
+			"projects/my-project/agent/sessions/1234",
+			SessionPath{ProjectID: "my-project", SessionID: "1234"},
+			false,
+		},
+		{
+			"should parse environment session",
+			"projects/my-project/agent/environments/staging/users/-/sessions/1234",
+			SessionPath{ProjectID: "my-project", Environment: "staging", UserID: "-", SessionID: "1234"},
+			false,
+		},
+		{"should fail on empty path", "", SessionPath{}, true},
+		{"should fail on simple string", "session", SessionPath{}, true},
+		{"should fail on empty segment", "projects//agent/sessions/1234", SessionPath{}, true},
+		{"should fail on trailing slash", "projects/p/agent/sessions/1234/", SessionPath{}, true},
+		{"should fail on context path", "projects/p/agent/sessions/1234/contexts/ctx", SessionPath{}, true},
+		{"should fail on wrong keyword", "projects/p/agents/sessions/1234", SessionPath{}, true},
+		{"should fail on missing user", "projects/p/agent/environments/staging/sessions/1234", SessionPath{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSessionPath(tt.in)
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrMalformedSession), "expected ErrMalformedSession, got %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.in, got.String(), "should format back to the same path")
+		})
+	}
+}
+
+func TestSessionPath_Names(t *testing.T) {
+	p := SessionPath{ProjectID: "p", SessionID: "s"}
+	assert.Equal(t, "projects/p/agent/sessions/s/contexts/order", p.Context("order"))
+	assert.Equal(t, "projects/p/agent/sessions/s/entityTypes/fruit", p.EntityType("fruit"))
+
+	p = SessionPath{ProjectID: "p", Environment: "e", UserID: "u", SessionID: "s"}
+	assert.Equal(t, "projects/p/agent/environments/e/users/u/sessions/s/contexts/order", p.Context("order"))
+	assert.Equal(t, "projects/p/agent/environments/e/users/u/sessions/s/entityTypes/fruit", p.EntityType("fruit"))
+}
+
+func TestRequest_SessionPath(t *testing.T) {
+	rw := &Request{Session: "projects/p/agent/sessions/s"}
+	got, err := rw.SessionPath()
+	assert.NoError(t, err)
+	assert.Equal(t, SessionPath{ProjectID: "p", SessionID: "s"}, got)
+}
@@ -0,0 +1,78 @@
+package dialogflow
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Suffixes of the system contexts dialogflow uses while filling the slots of
+// an intent
+const (
+	dialogContextSuffix = "_dialog_context"
+	dialogParamsInfix   = "_dialog_params_"
+)
+
+// SlotFilling describes a webhook call made while dialogflow is still
+// collecting the required parameters of the intent, which happens when
+// "use webhook for slot filling" is enabled
+type SlotFilling struct {
+	// Param is the name of the required parameter dialogflow is asking for.
+	// It may be empty if dialogflow didn't send the matching context.
+	Param string
+
+	req *Request
+}
+
+// SlotFilling returns the slot filling information of the request. The
+// boolean is false if all the required parameters are present, which means
+// this is the final call for the intent.
+func (rw *Request) SlotFilling() (*SlotFilling, bool) {
+	if rw.QueryResult.AllRequiredParamsPresent {
+		return nil, false
+	}
+	s := &SlotFilling{req: rw}
+	for _, c := range rw.QueryResult.OutputContexts {
+		name := c.ShortName()
+		if i := strings.LastIndex(name, dialogParamsInfix); i >= 0 {
+			s.Param = name[i+len(dialogParamsInfix):]
+			break
+		}
+	}
+	return s, true
+}
+
+// Missing returns the sorted names of the parameters of the intent that
+// aren't filled yet
+func (s *SlotFilling) Missing() []string {
+	var missing []string
+	var params map[string]json.RawMessage
+	json.Unmarshal(s.req.QueryResult.Parameters, &params)
+	p := Params{values: params}
+	for _, name := range p.Names() {
+		if !strings.HasSuffix(name, ".original") && !p.IsSet(name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// Prompt builds the fulfillment asking the user for the parameter. The
+// system slot filling contexts are kept alive so that dialogflow goes on
+// filling the slots of the intent with the next user input.
+func (s *SlotFilling) Prompt(text string) *Fulfillment {
+	dff := &Fulfillment{FulfillmentText: text}
+	cm := NewContextManager(s.req)
+	for _, c := range s.req.QueryResult.OutputContexts {
+		name := c.ShortName()
+		if !strings.HasSuffix(name, dialogContextSuffix) && !strings.Contains(name, dialogParamsInfix) {
+			continue
+		}
+		if c.LifespanCount > 0 {
+			cm.Carry(name)
+		} else {
+			cm.Extend(name, 1)
+		}
+	}
+	cm.Apply(dff)
+	return dff
+}
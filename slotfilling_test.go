@@ -0,0 +1,53 @@
+package dialogflow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func slotFillingRequest() *Request {
+	return &Request{
+		Session: "projects/p/agent/sessions/s",
+		QueryResult: QueryResult{
+			AllRequiredParamsPresent: false,
+			Parameters:               json.RawMessage(`{"size": "large", "size.original": "big", "topping": "", "address": ""}`),
+			OutputContexts: Contexts{
+				{"projects/p/agent/sessions/s/contexts/order", 5, json.RawMessage(`{"size": "large"}`)},
+				{"projects/p/agent/sessions/s/contexts/e3e4d0a4-ab12_id_dialog_context", 2, json.RawMessage(`{"size": "large"}`)},
+				{"projects/p/agent/sessions/s/contexts/order_pizza_dialog_context", 2, nil},
+				{"projects/p/agent/sessions/s/contexts/order_pizza_dialog_params_topping", 0, json.RawMessage(`{"size": "large"}`)},
+			},
+		},
+	}
+}
+
+func TestRequest_SlotFilling(t *testing.T) {
+	rw := slotFillingRequest()
+	s, ok := rw.SlotFilling()
+	assert.True(t, ok)
+	assert.Equal(t, "topping", s.Param)
+	assert.Equal(t, []string{"address", "topping"}, s.Missing())
+
+	rw.QueryResult.OutputContexts = nil
+	s, ok = rw.SlotFilling()
+	assert.True(t, ok)
+	assert.Equal(t, "", s.Param, "should not know the parameter without contexts")
+
+	rw.QueryResult.AllRequiredParamsPresent = true
+	_, ok = rw.SlotFilling()
+	assert.False(t, ok, "should be the final call")
+}
+
+func TestSlotFilling_Prompt(t *testing.T) {
+	s, _ := slotFillingRequest().SlotFilling()
+	dff := s.Prompt("Which topping would you like on your large pizza?")
+	assert.Equal(t, "Which topping would you like on your large pizza?", dff.FulfillmentText)
+	want := Contexts{
+		{"projects/p/agent/sessions/s/contexts/e3e4d0a4-ab12_id_dialog_context", 2, json.RawMessage(`{"size": "large"}`)},
+		{"projects/p/agent/sessions/s/contexts/order_pizza_dialog_context", 2, nil},
+		{"projects/p/agent/sessions/s/contexts/order_pizza_dialog_params_topping", 1, json.RawMessage(`{"size": "large"}`)},
+	}
+	assert.Equal(t, want, dff.OutputContexts)
+}
@@ -0,0 +1,63 @@
+package dialogflow
+
+import "strings"
+
+// Surface capabilities
+const (
+	CapabilityScreenOutput       = "actions.capability.SCREEN_OUTPUT"
+	CapabilityAudioOutput        = "actions.capability.AUDIO_OUTPUT"
+	CapabilityWebBrowser         = "actions.capability.WEB_BROWSER"
+	CapabilityMediaResponseAudio = "actions.capability.MEDIA_RESPONSE_AUDIO"
+)
+
+// HasCapability returns true if the surface the user is interacting with has
+// the given capability. Both the surface of the Actions on Google payload and
+// the matching context (actions_capability_screen_output for
+// actions.capability.SCREEN_OUTPUT) are checked.
+func (rw *Request) HasCapability(name string) bool {
+	if p, err := rw.GooglePayload(); err == nil && p.Surface.HasCapability(name) {
+		return true
+	}
+	return rw.hasContext(strings.ToLower(strings.Replace(name, ".", "_", -1)))
+}
+
+// HasScreen returns true if the surface can display things
+func (rw *Request) HasScreen() bool {
+	return rw.HasCapability(CapabilityScreenOutput)
+}
+
+// HasAudio returns true if the surface can play audio
+func (rw *Request) HasAudio() bool {
+	return rw.HasCapability(CapabilityAudioOutput)
+}
+
+// HasWebBrowser returns true if the surface can open links
+func (rw *Request) HasWebBrowser() bool {
+	return rw.HasCapability(CapabilityWebBrowser)
+}
+
+// HasMediaResponseAudio returns true if the surface supports media responses
+func (rw *Request) HasMediaResponseAudio() bool {
+	return rw.HasCapability(CapabilityMediaResponseAudio)
+}
+
+// CanSwitchToScreenDevice returns true if the user has another surface with
+// a screen available, to which the conversation can be transferred
+func (rw *Request) CanSwitchToScreenDevice() bool {
+	p, err := rw.GooglePayload()
+	if err != nil {
+		return false
+	}
+	for _, s := range p.AvailableSurfaces {
+		if s.HasCapability(CapabilityScreenOutput) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasContext returns true if an output context has the given short name
+func (rw *Request) hasContext(name string) bool {
+	_, err := rw.QueryResult.OutputContexts.Get(name)
+	return err == nil
+}
@@ -0,0 +1,58 @@
+package dialogflow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequest_Capabilities(t *testing.T) {
+	var google Request
+	if err := json.Unmarshal([]byte(googleRequest), &google); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	speaker := &Request{
+		OriginalDetectIntentRequest: OriginalDetectIntentRequest{
+			Source:  "google",
+			Payload: []byte(`{"surface": {"capabilities": [{"name": "actions.capability.AUDIO_OUTPUT"}]}}`),
+		},
+	}
+	contexts := &Request{
+		QueryResult: QueryResult{OutputContexts: Contexts{
+			{"projects/p/agent/sessions/s/contexts/actions_capability_screen_output", 0, nil},
+			{"projects/p/agent/sessions/s/contexts/actions_capability_audio_output", 0, nil},
+			{"projects/p/agent/sessions/s/contexts/google_assistant_input_type_keyboard", 0, nil},
+		}},
+	}
+	prefixed := &Request{
+		QueryResult: QueryResult{OutputContexts: Contexts{
+			{"projects/p/agent/sessions/s/contexts/no_actions_capability_web_browser", 0, nil},
+		}},
+	}
+
+	tests := []struct {
+		name      string
+		req       *Request
+		screen    bool
+		audio     bool
+		web       bool
+		media     bool
+		canSwitch bool
+	}{
+		{"smartphone", &google, true, true, true, true, true},
+		{"speaker", speaker, false, true, false, false, false},
+		{"contexts only", contexts, true, true, false, false, false},
+		{"prefixed context", prefixed, false, false, false, false, false},
+		{"empty request", &Request{}, false, false, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.screen, tt.req.HasScreen(), "HasScreen")
+			assert.Equal(t, tt.audio, tt.req.HasAudio(), "HasAudio")
+			assert.Equal(t, tt.web, tt.req.HasWebBrowser(), "HasWebBrowser")
+			assert.Equal(t, tt.media, tt.req.HasMediaResponseAudio(), "HasMediaResponseAudio")
+			assert.Equal(t, tt.canSwitch, tt.req.CanSwitchToScreenDevice(), "CanSwitchToScreenDevice")
+		})
+	}
+}
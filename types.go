@@ -125,6 +125,12 @@ func (p PayloadWrapper) MarshalJSON() ([]byte, error) {
 	return json.Marshal(p.Payload)
 }
 
+// UnmarshalJSON implements the Unmarshaler interface and will unmarshal the
+// whole data to the payload
+func (p *PayloadWrapper) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &p.Payload)
+}
+
 // GetKey implements the RichMessage interface and returns the JSON key
 // associated to the Payload type
 func (p PayloadWrapper) GetKey() string {
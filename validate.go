@@ -0,0 +1,220 @@
+package dialogflow
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError is returned by GetValidParams when a parameter doesn't
+// satisfy its validation rules
+type ValidationError struct {
+	Field    string       // Name of the struct field
+	Param    string       // Name of the dialogflow parameter
+	Rule     string       // The rule that failed, such as "required" or "min=18"
+	Prompt   string       // The prompt associated to the field, if any
+	Reprompt *Fulfillment // A fulfillment asking the user for the parameter again
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("dialogflow: parameter %q doesn't satisfy %q", e.Param, e.Rule)
+}
+
+// GetValidParams unmarshals the parameters to the given struct, like
+// GetParams, then validates them using the rules found in the validate tag
+// of each field. The supported rules are :
+//
+//	required      the value must not be empty
+//	min=n, max=n  bounds of numbers, or length of strings and slices
+//	oneof=a b c   the value must be one of the space separated values
+//	pattern=re    strings must match the regular expression, this rule must
+//	              be the last one since the expression may contain commas
+//
+// Rules other than required are skipped for empty values. The first invalid
+// field is returned as a *ValidationError, whose Reprompt asks the user
+// again using the text of the prompt tag, and keeps the current contexts
+// alive.
+func (rw *Request) GetValidParams(i interface{}) error {
+	if err := rw.GetParams(i); err != nil {
+		return err
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(i))
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("dialogflow: GetValidParams expects a pointer to a struct, got %T", i)
+	}
+	t := v.Type()
+	for n := 0; n < t.NumField(); n++ {
+		f := t.Field(n)
+		tag, ok := f.Tag.Lookup("validate")
+		if !ok {
+			continue
+		}
+		rule, err := validateField(v.Field(n), tag)
+		if err != nil {
+			return fmt.Errorf("dialogflow: invalid validation on field %s: %v", f.Name, err)
+		}
+		if rule != "" {
+			verr := &ValidationError{Field: f.Name, Param: paramName(f), Rule: rule, Prompt: f.Tag.Get("prompt")}
+			verr.Reprompt = rw.reprompt(verr)
+			return verr
+		}
+	}
+	return nil
+}
+
+// reprompt builds the fulfillment asking the user for the invalid parameter
+// again, extending the incoming user contexts that are still alive by one
+// turn
+func (rw *Request) reprompt(verr *ValidationError) *Fulfillment {
+	text := verr.Prompt
+	if text == "" {
+		text = fmt.Sprintf("Sorry, could you give me the %s again?", verr.Param)
+	}
+	dff := &Fulfillment{FulfillmentText: text}
+	cm := NewContextManager(rw)
+	for _, c := range rw.QueryResult.OutputContexts {
+		if c == nil || c.LifespanCount <= 0 || isSystemContext(c.ShortName()) {
+			continue
+		}
+		cm.Extend(c.ShortName(), 1)
+	}
+	cm.Apply(dff)
+	return dff
+}
+
+// paramName returns the name of the parameter associated to the field,
+// using its json tag
+func paramName(f reflect.StructField) string {
+	if name := strings.Split(f.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+	return f.Name
+}
+
+// validateField checks the value against the rules of the tag, and returns
+// the first rule that failed or an empty string. An error is returned if the
+// tag itself is invalid.
+func validateField(v reflect.Value, tag string) (string, error) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v = reflect.Zero(v.Type().Elem())
+			break
+		}
+		v = v.Elem()
+	}
+
+	rules := splitRules(tag)
+	for _, r := range rules {
+		if r == "required" && v.IsZero() {
+			return r, nil
+		}
+	}
+	if v.IsZero() {
+		return "", nil
+	}
+
+	for _, r := range rules {
+		name, arg := r, ""
+		if i := strings.Index(r, "="); i >= 0 {
+			name, arg = r[:i], r[i+1:]
+		}
+		var ok bool
+		var err error
+		switch name {
+		case "required":
+			continue
+		case "min", "max":
+			ok, err = checkBound(v, name, arg)
+		case "oneof":
+			ok = checkOneOf(v, arg)
+		case "pattern":
+			ok, err = checkPattern(v, arg)
+		default:
+			err = fmt.Errorf("unknown rule %q", name)
+		}
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return r, nil
+		}
+	}
+	return "", nil
+}
+
+// splitRules splits the validate tag on commas, keeping everything after
+// pattern= as a single rule
+func splitRules(tag string) []string {
+	var rules []string
+	for tag != "" {
+		if strings.HasPrefix(tag, "pattern=") {
+			return append(rules, tag)
+		}
+		i := strings.Index(tag, ",")
+		if i < 0 {
+			return append(rules, strings.TrimSpace(tag))
+		}
+		if r := strings.TrimSpace(tag[:i]); r != "" {
+			rules = append(rules, r)
+		}
+		tag = strings.TrimSpace(tag[i+1:])
+	}
+	return rules
+}
+
+// checkBound checks the min or max rule. Numbers are compared to the bound,
+// strings and slices use their length.
+func checkBound(v reflect.Value, name, arg string) (bool, error) {
+	bound, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid bound %q", arg)
+	}
+	var n float64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	case reflect.String:
+		n = float64(utf8.RuneCountInString(v.String()))
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n = float64(v.Len())
+	default:
+		return false, fmt.Errorf("%s isn't supported on %s", name, v.Kind())
+	}
+	if name == "min" {
+		return n >= bound, nil
+	}
+	return n <= bound, nil
+}
+
+// checkOneOf checks that the value, formatted, is one of the space separated
+// values
+func checkOneOf(v reflect.Value, arg string) bool {
+	s := fmt.Sprint(v.Interface())
+	for _, o := range strings.Fields(arg) {
+		if s == o {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPattern checks that the string matches the regular expression
+func checkPattern(v reflect.Value, arg string) (bool, error) {
+	re, err := regexp.Compile(arg)
+	if err != nil {
+		return false, err
+	}
+	if v.Kind() != reflect.String {
+		return false, fmt.Errorf("pattern isn't supported on %s", v.Kind())
+	}
+	return re.MatchString(v.String()), nil
+}
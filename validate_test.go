@@ -0,0 +1,126 @@
+package dialogflow
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type signup struct {
+	Name     string   `json:"name" validate:"required,max=20" prompt:"What's your name?"`
+	Age      int      `json:"age" validate:"required,min=18,max=120" prompt:"How old are you?"`
+	Email    string   `json:"email" validate:"pattern=^[^@,]+@[^@,]+\\.[a-z]+$" prompt:"What's your email?"`
+	Size     string   `json:"size" validate:"oneof=small medium large"`
+	Score    *float64 `json:"score" validate:"min=0.5"`
+	Toppings []string `json:"toppings" validate:"max=2"`
+	Comment  string   `json:"comment"`
+}
+
+func TestRequest_GetValidParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		params string
+		field  string
+		param  string
+		rule   string
+		prompt string
+	}{
+		{"should be valid", `{"name": "John", "age": 30, "email": "john@example.com", "size": "small", "score": 0.9, "toppings": ["ham"]}`, "", "", "", ""},
+		{"should skip empty optional values", `{"name": "John", "age": 30, "email": "", "size": ""}`, "", "", "", ""},
+		{"should require name", `{"name": "", "age": 30}`, "Name", "name", "required", "What's your name?"},
+		{"should check string length", `{"name": "Johnathan Christopher Smith", "age": 30}`, "Name", "name", "max=20", "What's your name?"},
+		{"should require age", `{"name": "John"}`, "Age", "age", "required", "How old are you?"},
+		{"should check minimum", `{"name": "John", "age": 12}`, "Age", "age", "min=18", "How old are you?"},
+		{"should check maximum", `{"name": "John", "age": 150}`, "Age", "age", "max=120", "How old are you?"},
+		{"should check pattern", `{"name": "John", "age": 30, "email": "john"}`, "Email", "email", `pattern=^[^@,]+@[^@,]+\.[a-z]+$`, "What's your email?"},
+		{"should check oneof", `{"name": "John", "age": 30, "size": "huge"}`, "Size", "size", "oneof=small medium large", ""},
+		{"should check pointers", `{"name": "John", "age": 30, "score": 0.1}`, "Score", "score", "min=0.5", ""},
+		{"should check slice length", `{"name": "John", "age": 30, "toppings": ["ham", "cheese", "olives"]}`, "Toppings", "toppings", "max=2", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := &Request{QueryResult: QueryResult{Parameters: json.RawMessage(tt.params)}}
+			var s signup
+			err := rw.GetValidParams(&s)
+			if tt.field == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var verr *ValidationError
+			if !assert.True(t, errors.As(err, &verr), "expected a ValidationError, got %v", err) {
+				return
+			}
+			assert.Equal(t, tt.field, verr.Field)
+			assert.Equal(t, tt.param, verr.Param)
+			assert.Equal(t, tt.rule, verr.Rule)
+			assert.Equal(t, tt.prompt, verr.Prompt)
+		})
+	}
+}
+
+func TestRequest_GetValidParams_Reprompt(t *testing.T) {
+	rw := &Request{
+		Session: "projects/p/agent/sessions/s",
+		QueryResult: QueryResult{
+			Parameters: json.RawMessage(`{"name": "John", "age": 12, "size": "huge"}`),
+			OutputContexts: Contexts{
+				{"projects/p/agent/sessions/s/contexts/signup", 1, json.RawMessage(`{"name": "John"}`)},
+				{"projects/p/agent/sessions/s/contexts/expired", 0, nil},
+				{"projects/p/agent/sessions/s/contexts/actions_capability_screen_output", 0, nil},
+				{"projects/p/agent/sessions/s/contexts/__system_counters__", 1, json.RawMessage(`{"no-input": 0}`)},
+				nil,
+			},
+		},
+	}
+	var s signup
+	err := rw.GetValidParams(&s)
+	verr, ok := err.(*ValidationError)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, "How old are you?", verr.Reprompt.FulfillmentText)
+	assert.Equal(t, Contexts{{"projects/p/agent/sessions/s/contexts/signup", 2, json.RawMessage(`{"name": "John"}`)}}, verr.Reprompt.OutputContexts, "should only extend live user contexts")
+	assert.Equal(t, `dialogflow: parameter "age" doesn't satisfy "min=18"`, verr.Error())
+
+	rw.QueryResult.Parameters = json.RawMessage(`{"name": "John", "age": 30, "size": "huge"}`)
+	err = rw.GetValidParams(&s)
+	verr, _ = err.(*ValidationError)
+	assert.Equal(t, "Sorry, could you give me the size again?", verr.Reprompt.FulfillmentText, "should use the default prompt")
+}
+
+func TestRequest_GetValidParams_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params string
+		in     interface{}
+	}{
+		{"should fail on invalid parameters", ``, &signup{}},
+		{"should fail on non struct", `{"a": 1}`, &map[string]interface{}{}},
+		{"should fail on unknown rule", `{"a": "x"}`, &struct {
+			A string `json:"a" validate:"email"`
+		}{}},
+		{"should fail on invalid bound", `{"a": 1}`, &struct {
+			A int `json:"a" validate:"min=ten"`
+		}{}},
+		{"should fail on unsupported bound", `{"a": true}`, &struct {
+			A bool `json:"a" validate:"min=1"`
+		}{}},
+		{"should fail on invalid pattern", `{"a": "x"}`, &struct {
+			A string `json:"a" validate:"pattern=("`
+		}{}},
+		{"should fail on unsupported pattern", `{"a": 1}`, &struct {
+			A int `json:"a" validate:"pattern=^1$"`
+		}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := &Request{QueryResult: QueryResult{Parameters: json.RawMessage(tt.params)}}
+			err := rw.GetValidParams(tt.in)
+			assert.Error(t, err)
+			_, ok := err.(*ValidationError)
+			assert.False(t, ok, "should not be a validation error")
+		})
+	}
+}